@@ -8,6 +8,7 @@ require (
 	github.com/sensu/sensu-go/types v0.3.0
 	github.com/sensu/sensu-licensing v0.1.2
 	github.com/spf13/cobra v1.0.0
+	github.com/spf13/pflag v1.0.3
 	github.com/spf13/viper v1.7.0
 	github.com/stretchr/testify v1.6.0
 )
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+)
+
+const (
+	// DefaultAgentURL is the URL of the events API of a local agent.
+	DefaultAgentURL = "http://127.0.0.1:3031"
+
+	// DefaultAgentSocket is the address of the TCP socket of a local agent.
+	DefaultAgentSocket = "127.0.0.1:3030"
+)
+
+// AgentClientConfig contains the configuration information needed for an
+// AgentClient.
+type AgentClientConfig struct {
+	// URL is the URL of the agent API. DefaultAgentURL is used when empty.
+	URL string
+
+	// Socket is the address of the agent TCP socket. DefaultAgentSocket is
+	// used when empty.
+	Socket string
+}
+
+// AgentClient publishes events to the events API or the socket of a Sensu
+// agent, which forwards them to the backend with its own entity unless the
+// events specify another one.
+type AgentClient struct {
+	HTTPClient http.Client
+	Config     AgentClientConfig
+}
+
+// NewAgentClient creates a new agent client that uses the supplied
+// AgentClientConfig.
+func NewAgentClient(config AgentClientConfig) *AgentClient {
+	if config.URL == "" {
+		config.URL = DefaultAgentURL
+	}
+	if config.Socket == "" {
+		config.Socket = DefaultAgentSocket
+	}
+	return &AgentClient{
+		Config: config,
+	}
+}
+
+// PublishEvent validates the event and sends it to the events API of the
+// agent. The entity of the event is optional, the agent entity is used when
+// it is nil.
+//
+// If the agent rejects the event with a 4xx or 5xx status, an HTTPError is
+// returned with the status code and the first 64KB of the response body.
+func (c *AgentClient) PublishEvent(ctx context.Context, event *corev2.Event) error {
+	if event.Check == nil {
+		return fmt.Errorf("event must contain a check")
+	}
+	if event.Entity != nil {
+		if err := prepareEvent(event); err != nil {
+			return err
+		}
+	} else if err := event.Check.Validate(); err != nil {
+		return fmt.Errorf("check is invalid: %s", err)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := newHTTPRequest(ctx, http.MethodPost, c.Config.URL+"/events", body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return validateResponse(resp)
+}
+
+// socketCheckResult is the check result format accepted by the agent socket.
+type socketCheckResult struct {
+	Name     string   `json:"name"`
+	Output   string   `json:"output"`
+	Status   uint32   `json:"status"`
+	Source   string   `json:"source,omitempty"`
+	Handlers []string `json:"handlers,omitempty"`
+	Interval uint32   `json:"interval,omitempty"`
+	TTL      int64    `json:"ttl,omitempty"`
+	Executed int64    `json:"executed,omitempty"`
+}
+
+// PublishEventToSocket sends the check result of the event to the TCP socket
+// of the agent. The socket only accepts the name, output, status, handlers,
+// interval, TTL and execution time of the check. The entity name, if any, is
+// sent as the source of the result, for a proxy entity.
+func (c *AgentClient) PublishEventToSocket(ctx context.Context, event *corev2.Event) error {
+	if event.Check == nil {
+		return fmt.Errorf("event must contain a check")
+	}
+	if err := event.Check.Validate(); err != nil {
+		return fmt.Errorf("check is invalid: %s", err)
+	}
+	result := socketCheckResult{
+		Name:     event.Check.Name,
+		Output:   event.Check.Output,
+		Status:   event.Check.Status,
+		Handlers: event.Check.Handlers,
+		Interval: event.Check.Interval,
+		TTL:      event.Check.Ttl,
+		Executed: event.Check.Executed,
+	}
+	if event.Entity != nil {
+		result.Source = event.Entity.Name
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.Config.Socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	} else if err := conn.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		return err
+	}
+	if _, err := conn.Write(body); err != nil {
+		return err
+	}
+	return nil
+}
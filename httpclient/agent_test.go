@@ -0,0 +1,94 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+func TestAgentClientPublishEvent(t *testing.T) {
+	var published corev2.Event
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.URL.Path != "/events" {
+			t.Errorf("bad request: %s %s", req.Method, req.URL.Path)
+		}
+		if err := json.NewDecoder(req.Body).Decode(&published); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer agent.Close()
+
+	cl := httpclient.NewAgentClient(httpclient.AgentClientConfig{URL: agent.URL})
+	event := httpclient.NewEvent("default", "server1", "handler-failure", 1, "retrying")
+	// Use the agent entity
+	event.Entity = nil
+	if err := cl.PublishEvent(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if published.Entity != nil {
+		t.Fatalf("unexpected entity: %+v", published.Entity)
+	}
+	if published.Check == nil || published.Check.Name != "handler-failure" || published.Check.Status != 1 {
+		t.Fatalf("bad published check: %+v", published.Check)
+	}
+}
+
+func TestAgentClientPublishEventError(t *testing.T) {
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "queue full", http.StatusServiceUnavailable)
+	}))
+	defer agent.Close()
+
+	cl := httpclient.NewAgentClient(httpclient.AgentClientConfig{URL: agent.URL})
+	event := httpclient.NewEvent("default", "server1", "handler-failure", 1, "retrying")
+	err := cl.PublishEvent(context.Background(), event)
+	if httpErr, ok := err.(httpclient.HTTPError); !ok || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 HTTPError, got %v", err)
+	}
+	if err := cl.PublishEvent(context.Background(), &corev2.Event{}); err == nil {
+		t.Fatal("expected an error for an event without check")
+	}
+}
+
+func TestAgentClientPublishEventToSocket(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		b, _ := ioutil.ReadAll(conn)
+		received <- b
+	}()
+
+	cl := httpclient.NewAgentClient(httpclient.AgentClientConfig{Socket: listener.Addr().String()})
+	event := httpclient.NewEvent("default", "switch1", "check-ping", 2, "unreachable")
+	event.Check.Handlers = []string{"slack"}
+	if err := cl.PublishEventToSocket(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(<-received, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result["name"] != "check-ping" || result["output"] != "unreachable" || result["status"] != float64(2) || result["source"] != "switch1" {
+		t.Fatalf("bad check result: %v", result)
+	}
+	if handlers, ok := result["handlers"].([]interface{}); !ok || len(handlers) != 1 || handlers[0] != "slack" {
+		t.Fatalf("bad handlers: %v", result["handlers"])
+	}
+}
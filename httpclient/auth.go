@@ -0,0 +1,119 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// tokenExpiryMargin is how long before their expiry access tokens are
+// refreshed, to account for clock skew and request latency.
+const tokenExpiryMargin = 10 * time.Second
+
+// authTokens are the tokens returned by the /auth endpoints of the backend.
+type authTokens struct {
+	Access    string `json:"access_token"`
+	ExpiresAt int64  `json:"expires_at"`
+	Refresh   string `json:"refresh_token"`
+}
+
+func (t *authTokens) expired() bool {
+	expiry := time.Unix(t.ExpiresAt, 0).Add(-tokenExpiryMargin)
+	return !time.Now().Before(expiry)
+}
+
+// authorize sets the Authorization header of the request. The API key is
+// used unless the client is configured with a username, in which case an
+// access token is used and returned.
+func (c *CoreClient) authorize(req *http.Request) (string, error) {
+	if c.Config.APIKey != "" || c.Config.Username == "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Key %s", c.Config.APIKey))
+		return "", nil
+	}
+	token, err := c.accessToken(req.Context())
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	return token, nil
+}
+
+// accessToken returns a valid access token, refreshing the current tokens or
+// authenticating again if needed.
+func (c *CoreClient) accessToken(ctx context.Context) (string, error) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	if c.tokens != nil && !c.tokens.expired() {
+		return c.tokens.Access, nil
+	}
+	if c.tokens != nil && c.tokens.Refresh != "" {
+		tokens, err := c.refreshTokens(ctx, c.tokens)
+		if err == nil {
+			c.tokens = tokens
+			return tokens.Access, nil
+		}
+	}
+	tokens, err := c.login(ctx)
+	if err != nil {
+		c.tokens = nil
+		return "", fmt.Errorf("error authenticating as %s: %s", c.Config.Username, err)
+	}
+	c.tokens = tokens
+	return tokens.Access, nil
+}
+
+// expireToken marks the access token as expired, unless it has already been
+// replaced by another request.
+func (c *CoreClient) expireToken(token string) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	if c.tokens != nil && c.tokens.Access == token {
+		c.tokens.ExpiresAt = 0
+	}
+}
+
+// login obtains new tokens with the username and password.
+func (c *CoreClient) login(ctx context.Context) (*authTokens, error) {
+	req, err := newHTTPRequest(ctx, http.MethodGet, c.Backend()+"/auth", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.Config.Username, c.Config.Password)
+	return c.requestTokens(req)
+}
+
+// refreshTokens obtains new tokens with the refresh token.
+func (c *CoreClient) refreshTokens(ctx context.Context, tokens *authTokens) (*authTokens, error) {
+	body, err := json.Marshal(map[string]string{"refresh_token": tokens.Refresh})
+	if err != nil {
+		return nil, err
+	}
+	req, err := newHTTPRequest(ctx, http.MethodPost, c.Backend()+"/auth/token", body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokens.Access))
+	return c.requestTokens(req)
+}
+
+func (c *CoreClient) requestTokens(req *http.Request) (*authTokens, error) {
+	resp, err := c.failover(req, c.roundTrip)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := validateResponse(resp); err != nil {
+		return nil, err
+	}
+	var tokens authTokens
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&tokens); err != nil {
+		return nil, err
+	}
+	if tokens.Access == "" {
+		return nil, fmt.Errorf("no access token returned")
+	}
+	return &tokens, nil
+}
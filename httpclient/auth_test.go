@@ -0,0 +1,212 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+// authBackend is a fake backend issuing tokens to the admin user.
+type authBackend struct {
+	mu        sync.Mutex
+	lifetime  time.Duration
+	issued    int
+	logins    int
+	refreshes int
+	valid     map[string]bool
+	refresh   map[string]string
+}
+
+func newAuthBackend(lifetime time.Duration) *authBackend {
+	return &authBackend{
+		lifetime: lifetime,
+		valid:    map[string]bool{},
+		refresh:  map[string]string{},
+	}
+}
+
+func (b *authBackend) issue(w http.ResponseWriter) {
+	b.issued++
+	access := fmt.Sprintf("access-%d", b.issued)
+	refresh := fmt.Sprintf("refresh-%d", b.issued)
+	b.valid[access] = true
+	b.refresh[refresh] = access
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  access,
+		"expires_at":    time.Now().Add(b.lifetime).Unix(),
+		"refresh_token": refresh,
+	})
+}
+
+// revoke invalidates all the access tokens issued so far.
+func (b *authBackend) revoke() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.valid = map[string]bool{}
+}
+
+func (b *authBackend) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch req.URL.Path {
+	case "/auth":
+		username, password, ok := req.BasicAuth()
+		if !ok || username != "admin" || password != "P@ssw0rd!" {
+			http.Error(w, "bad credentials", http.StatusUnauthorized)
+			return
+		}
+		b.logins++
+		b.issue(w)
+	case "/auth/token":
+		var body map[string]string
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		access, ok := b.refresh[body["refresh_token"]]
+		if !ok || req.Header.Get("Authorization") != "Bearer "+access {
+			http.Error(w, "bad refresh token", http.StatusUnauthorized)
+			return
+		}
+		delete(b.refresh, body["refresh_token"])
+		b.refreshes++
+		b.issue(w)
+	default:
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !b.valid[token] {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+			return
+		}
+		if req.Method == http.MethodPut {
+			body, _ := ioutil.ReadAll(req.Body)
+			if len(body) == 0 {
+				http.Error(w, "empty body", http.StatusBadRequest)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newAuthClient(t *testing.T, backend *authBackend, password string) (*httpclient.CoreClient, func()) {
+	t.Helper()
+	server := httptest.NewTLSServer(backend)
+	config := httpclient.CoreClientConfig{
+		URL:      server.URL,
+		Username: "admin",
+		Password: password,
+		CACert:   server.Certificate(),
+	}
+	return httpclient.NewCoreClient(config), server.Close
+}
+
+func TestClientUsernamePassword(t *testing.T) {
+	backend := newAuthBackend(time.Hour)
+	cl, cleanup := newAuthClient(t, backend, "P@ssw0rd!")
+	defer cleanup()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	for i := 0; i < 3; i++ {
+		if _, err := cl.PutResource(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backend.logins != 1 {
+		t.Errorf("expected 1 login, got %d", backend.logins)
+	}
+	if backend.refreshes != 0 {
+		t.Errorf("expected no refreshes, got %d", backend.refreshes)
+	}
+}
+
+func TestClientBadPassword(t *testing.T) {
+	backend := newAuthBackend(time.Hour)
+	cl, cleanup := newAuthClient(t, backend, "hunter2")
+	defer cleanup()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	_, err := cl.DeleteResource(context.Background(), req)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "error authenticating as admin") {
+		t.Fatalf("bad error: %s", err)
+	}
+}
+
+func TestClientRefreshExpiredToken(t *testing.T) {
+	// Tokens expiring within the refresh margin are refreshed before use
+	backend := newAuthBackend(time.Second)
+	cl, cleanup := newAuthClient(t, backend, "P@ssw0rd!")
+	defer cleanup()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	for i := 0; i < 3; i++ {
+		if _, err := cl.DeleteResource(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backend.logins != 1 {
+		t.Errorf("expected 1 login, got %d", backend.logins)
+	}
+	if backend.refreshes != 2 {
+		t.Errorf("expected 2 refreshes, got %d", backend.refreshes)
+	}
+}
+
+func TestClientRefreshRejectedToken(t *testing.T) {
+	backend := newAuthBackend(time.Hour)
+	cl, cleanup := newAuthClient(t, backend, "P@ssw0rd!")
+	defer cleanup()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	if _, err := cl.PutResource(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	backend.revoke()
+	// The request body must be sent again with the refreshed token
+	if _, err := cl.PutResource(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if backend.refreshes != 1 {
+		t.Errorf("expected 1 refresh, got %d", backend.refreshes)
+	}
+}
+
+func TestClientConcurrentAuthentication(t *testing.T) {
+	backend := newAuthBackend(time.Hour)
+	cl, cleanup := newAuthClient(t, backend, "P@ssw0rd!")
+	defer cleanup()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cl.PutResource(context.Background(), req)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backend.logins != 1 {
+		t.Errorf("expected 1 login, got %d", backend.logins)
+	}
+}
@@ -11,7 +11,11 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"path"
+	"reflect"
+	"strconv"
+	"sync"
 
 	corev2 "github.com/sensu/sensu-go/api/core/v2"
 	"github.com/sensu/sensu-go/types"
@@ -57,6 +61,12 @@ func NewResourceRequest(apiVersion, typeName, namespace, name string) (ResourceR
 	}, nil
 }
 
+// NewListRequest creates a ResourceRequest for listing the resources of a type
+// in a namespace with ListResources.
+func NewListRequest(apiVersion, typeName, namespace string) (ResourceRequest, error) {
+	return NewResourceRequest(apiVersion, typeName, namespace, "")
+}
+
 // NewEventRequest creates a request for an Event, based on its entity and
 // check name.
 func NewEventRequest(namespace, entity, check string) ResourceRequest {
@@ -87,19 +97,51 @@ func NewEventRequest(namespace, entity, check string) ResourceRequest {
 }
 
 // HTTPError is an error type that holds the HTTP response code and body.
+// When the body is a Sensu API error, its message and code are decoded into
+// Message and Code.
 type HTTPError struct {
 	StatusCode int
 	Body       string
+
+	// Message is the message of the Sensu API error, if any.
+	Message string
+
+	// Code is the code of the Sensu API error, if any.
+	Code int
 }
 
 func (h HTTPError) Error() string {
+	if h.Message != "" {
+		return fmt.Sprintf("error %d: %s", h.StatusCode, h.Message)
+	}
 	return fmt.Sprintf("error %d: %s", h.StatusCode, h.Body)
 }
 
+// Is reports whether the HTTPError matches ErrNotFound or ErrForbidden, for
+// use with errors.Is.
+func (h HTTPError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return h.StatusCode == http.StatusNotFound
+	case ErrForbidden:
+		return h.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
 // CoreClient is a simple HTTP client for accessing the core Sensu API.
 type CoreClient struct {
 	HTTPClient http.Client
 	Config     CoreClientConfig
+
+	// authMu guards tokens, the access and refresh tokens obtained with the
+	// Username and Password of the Config.
+	authMu sync.Mutex
+	tokens *authTokens
+
+	// backendMu guards backend, the index of the backend URL in use.
+	backendMu sync.Mutex
+	backend   int
 }
 
 // CoreClientConfig contains the configuration information needed for a CoreClient.
@@ -107,43 +149,100 @@ type CoreClientConfig struct {
 	// URL is the server URL.
 	URL string
 
+	// URLs are the URLs of the backends of a cluster, used instead of URL
+	// when set. Requests are sent to the last healthy backend, and fail over
+	// to the next healthy one on connection errors and 5xx responses.
+	URLs []string
+
 	// APIKey is the Sensu API key.
 	APIKey string
 
+	// Username and Password are the credentials of a Sensu user, used when
+	// APIKey is empty. The client obtains an access token from the /auth
+	// endpoint, and refreshes it when it expires or is rejected.
+	Username string
+	Password string
+
 	// CACert, if non-nil, will be used to configure TLS communication. This
 	// is only needed when using a self-signed certificate.
 	CACert *x509.Certificate
 
+	// CACertPool, if non-nil, holds the root certificates used to verify the
+	// backend certificate, instead of the system pool and CACert. Use it with
+	// CA bundles, for example from SecurityConfig.GetCACertPool in the sensu
+	// package.
+	CACertPool *x509.CertPool
+
 	// InsecureSkipVerify disables TLS hostname verification. This should not
 	// be used outside of testing!
 	InsecureSkipVerify bool
+
+	// ClientCert and ClientKey are the paths of the PEM encoded certificate
+	// and private key presented to backends requiring mutual TLS. The key
+	// pair is loaded again when the files change, so that rotated
+	// certificates are used without restarting the process.
+	ClientCert string
+	ClientKey  string
+
+	// Retry is the policy for retrying failed requests. By default, requests
+	// are not retried.
+	Retry RetryPolicy
+
+	// Middleware wraps every request sent by the client, including retries
+	// and authentication requests, for tracing or instrumentation. The first
+	// middleware is the outermost one.
+	Middleware []Middleware
+
+	// Debug logs every request and response with the standard logger, see
+	// DebugLogger.
+	Debug bool
+}
+
+// ListOptions specifies the pagination and filtering of ListResources.
+type ListOptions struct {
+	// Limit is the maximum number of resources fetched per request. All the
+	// pages are fetched, following the continue token returned by the
+	// server. A Limit of 0 fetches all resources in a single request.
+	Limit int
+
+	// LabelSelector filters resources on their labels, for example
+	// "region == us-west-1".
+	LabelSelector string
+
+	// FieldSelector filters resources on their fields, for example
+	// "check.name == disk".
+	FieldSelector string
 }
 
-func newRequest(ctx context.Context, resource corev2.Resource, verb, server, apikey string) (*http.Request, error) {
-	var (
-		req      *http.Request
-		err      error
-		location = server + resource.URIPath()
-	)
+// continueHeader is the response header holding the token of the next page.
+const continueHeader = "Sensu-Continue"
+
+func newRequest(ctx context.Context, resource corev2.Resource, verb, server string) (*http.Request, error) {
+	location := server + resource.URIPath()
 
 	switch verb {
-	case http.MethodGet:
-		req, err = http.NewRequestWithContext(ctx, verb, location, nil)
+	case http.MethodGet, http.MethodDelete:
+		return newHTTPRequest(ctx, verb, location, nil)
 	case http.MethodPut, http.MethodPost:
-		body, berr := json.Marshal(resource)
-		if berr != nil {
-			return nil, berr
+		body, err := json.Marshal(resource)
+		if err != nil {
+			return nil, err
 		}
-		req, err = http.NewRequestWithContext(ctx, verb, location, bytes.NewReader(body))
-	case http.MethodDelete:
-		req, err = http.NewRequestWithContext(ctx, verb, location, nil)
+		return newHTTPRequest(ctx, verb, location, body)
 	default:
 		return nil, fmt.Errorf("method not supported: %s", verb)
 	}
+}
+
+func newHTTPRequest(ctx context.Context, verb, location string, body []byte) (*http.Request, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, verb, location, bodyReader)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", fmt.Sprintf("Key %s", apikey))
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 
@@ -163,11 +262,11 @@ func newRequest(ctx context.Context, resource corev2.Resource, verb, server, api
 // occurred, then a non-nil http.Response will be returned with its response
 // body closed.
 func (c *CoreClient) GetResource(ctx context.Context, r ResourceRequest, in types.Resource) (*http.Response, error) {
-	req, err := newRequest(ctx, r.Resource, http.MethodGet, c.Config.URL, c.Config.APIKey)
+	req, err := newRequest(ctx, r.Resource, http.MethodGet, c.Backend())
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -179,6 +278,144 @@ func (c *CoreClient) GetResource(ctx context.Context, r ResourceRequest, in type
 	return resp, json.NewDecoder(reader).Decode(&in)
 }
 
+// ListResources is a generic method for listing Sensu resources. Use
+// NewListRequest to create the ResourceRequest. The resources are decoded into
+// values of the type of the request, resolved with types.ResolveType.
+//
+// All the pages of resources are fetched, see ListOptions. If the request of
+// a page fails with a 4xx or 5xx status, an HTTPError is returned with the
+// status code and the first 64KB of the response body.
+func (c *CoreClient) ListResources(ctx context.Context, r ResourceRequest, options ListOptions) ([]types.Resource, error) {
+	result, err := c.list(ctx, r, options, "")
+	if err != nil {
+		return nil, err
+	}
+	return decodeResources(r, result.items)
+}
+
+// listResult holds the undecoded resources returned by list.
+type listResult struct {
+	items []json.RawMessage
+
+	// etag is the entity tag of the resources, when they were returned in a
+	// single page.
+	etag string
+
+	// notModified is true when the resources match the entity tag passed to
+	// list.
+	notModified bool
+}
+
+// list fetches all the pages of resources. When etag is not empty, it is sent
+// in the If-None-Match header of the first request.
+func (c *CoreClient) list(ctx context.Context, r ResourceRequest, options ListOptions, etag string) (listResult, error) {
+	if r.Resource == nil {
+		return listResult{}, fmt.Errorf("no resource type specified in %s", r)
+	}
+	query := url.Values{}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.Itoa(options.Limit))
+	}
+	if options.LabelSelector != "" {
+		query.Set("labelSelector", options.LabelSelector)
+	}
+	if options.FieldSelector != "" {
+		query.Set("fieldSelector", options.FieldSelector)
+	}
+
+	var result listResult
+	for {
+		location := c.Backend() + r.Resource.URIPath()
+		if len(query) > 0 {
+			location += "?" + query.Encode()
+		}
+		req, err := newHTTPRequest(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return listResult{}, err
+		}
+		if etag != "" && query.Get("continue") == "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		next, err := c.listPage(req, &result)
+		if err != nil || result.notModified {
+			return result, err
+		}
+		if next == "" || options.Limit <= 0 {
+			if query.Get("continue") != "" {
+				// Entity tags only describe a single page of resources
+				result.etag = ""
+			}
+			return result, nil
+		}
+		query.Set("continue", next)
+	}
+}
+
+// listPage fetches a page of resources into the result, and returns the
+// continue token of the next page if any.
+func (c *CoreClient) listPage(req *http.Request, result *listResult) (string, error) {
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		result.notModified = true
+		return "", nil
+	}
+	if err := validateResponse(resp); err != nil {
+		return "", err
+	}
+	var items []json.RawMessage
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<24)).Decode(&items); err != nil {
+		return "", err
+	}
+	result.items = append(result.items, items...)
+	result.etag = resp.Header.Get("ETag")
+	return resp.Header.Get(continueHeader), nil
+}
+
+// decodeResources decodes the resources into values of the type of the
+// request.
+func decodeResources(r ResourceRequest, items []json.RawMessage) ([]types.Resource, error) {
+	resources := make([]types.Resource, 0, len(items))
+	for _, item := range items {
+		resource, err := decodeResource(r, item)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+func decodeResource(r ResourceRequest, item json.RawMessage) (types.Resource, error) {
+	resource, err := newResource(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(item, resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// newResource creates an empty resource of the type of the request.
+func newResource(r ResourceRequest) (types.Resource, error) {
+	if r.APIVersion != "" && r.Type != "" {
+		return types.ResolveType(r.APIVersion, r.Type)
+	}
+	resourceType := reflect.TypeOf(r.Resource)
+	if resourceType.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("resource %s is not a pointer", r)
+	}
+	resource, ok := reflect.New(resourceType.Elem()).Interface().(types.Resource)
+	if !ok {
+		return nil, fmt.Errorf("can't create a resource for %s", r)
+	}
+	return resource, nil
+}
+
 // DeleteResource is a generic method for deleting a Sensu resource.
 //
 // If the server returns a reponse code greater than 400, an HTTPError will be
@@ -188,12 +425,12 @@ func (c *CoreClient) GetResource(ctx context.Context, r ResourceRequest, in type
 // occurred, then a non-nil http.Response will be returned with its response
 // body closed.
 func (c *CoreClient) DeleteResource(ctx context.Context, r ResourceRequest) (*http.Response, error) {
-	req, err := newRequest(ctx, r.Resource, http.MethodDelete, c.Config.URL, c.Config.APIKey)
+	req, err := newRequest(ctx, r.Resource, http.MethodDelete, c.Backend())
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -210,12 +447,12 @@ func (c *CoreClient) DeleteResource(ctx context.Context, r ResourceRequest) (*ht
 // occurred, then a non-nil http.Response will be returned with its response
 // body closed.
 func (c *CoreClient) PutResource(ctx context.Context, r ResourceRequest) (*http.Response, error) {
-	req, err := newRequest(ctx, r.Resource, http.MethodPut, c.Config.URL, c.Config.APIKey)
+	req, err := newRequest(ctx, r.Resource, http.MethodPut, c.Backend())
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -233,12 +470,12 @@ func (c *CoreClient) PutResource(ctx context.Context, r ResourceRequest) (*http.
 // occurred, then a non-nil http.Response will be returned with its response
 // body closed.
 func (c *CoreClient) PostResource(ctx context.Context, r ResourceRequest) (*http.Response, error) {
-	req, err := newRequest(ctx, r.Resource, http.MethodPost, c.Config.URL, c.Config.APIKey)
+	req, err := newRequest(ctx, r.Resource, http.MethodPost, c.Backend())
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -246,6 +483,59 @@ func (c *CoreClient) PostResource(ctx context.Context, r ResourceRequest) (*http
 	return resp, validateResponse(resp)
 }
 
+// do sends the request, retrying it according to the retry policy of the
+// client.
+func (c *CoreClient) do(req *http.Request) (*http.Response, error) {
+	return c.Config.Retry.do(req, func(req *http.Request) (*http.Response, error) {
+		// retries are sent to the backend the previous attempt failed over to
+		req, err := c.toBackend(req)
+		if err != nil {
+			return nil, err
+		}
+		return c.failover(req, c.send)
+	})
+}
+
+// send sends the request with the credentials of the client. When the access
+// token of a user is rejected, the token is refreshed and the request is sent
+// again.
+func (c *CoreClient) send(req *http.Request) (*http.Response, error) {
+	token, err := c.authorize(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.roundTrip(req)
+	if err != nil || token == "" || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+	c.expireToken(token)
+
+	retry, err := cloneRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.authorize(retry); err != nil {
+		return nil, err
+	}
+	return c.roundTrip(retry)
+}
+
+// cloneRequest clones the request so that it can be sent again, with a new
+// copy of its body.
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
 func validateResponse(resp *http.Response) error {
 	if resp.StatusCode < 400 {
 		return nil
@@ -254,10 +544,16 @@ func validateResponse(resp *http.Response) error {
 	if err != nil {
 		return err
 	}
-	return HTTPError{
+	httpErr := HTTPError{
 		StatusCode: resp.StatusCode,
 		Body:       string(body),
 	}
+	var apiErr apiError
+	if err := json.Unmarshal(body, &apiErr); err == nil {
+		httpErr.Message = apiErr.Message
+		httpErr.Code = apiErr.Code
+	}
+	return httpErr
 }
 
 // NewCoreClient creates a new core API client that uses the supplied CoreClientConfig.
@@ -268,7 +564,9 @@ func NewCoreClient(config CoreClientConfig) *CoreClient {
 	}
 	// Set up CA cert if provided. By default, the Go HTTP client uses the
 	// system cert pool.
-	if config.CACert != nil {
+	if config.CACertPool != nil {
+		setRootCAs(&client.HTTPClient, config.CACertPool)
+	} else if config.CACert != nil {
 		setCACert(&client.HTTPClient, config.CACert)
 	}
 
@@ -276,6 +574,11 @@ func NewCoreClient(config CoreClientConfig) *CoreClient {
 	if config.InsecureSkipVerify {
 		setInsecureSkipVerify(&client.HTTPClient)
 	}
+
+	// Set up the client certificate for mutual TLS if provided
+	if config.ClientCert != "" || config.ClientKey != "" {
+		setClientCertificate(&client.HTTPClient, newKeyPairReloader(config.ClientCert, config.ClientKey))
+	}
 	return client
 }
 
@@ -286,6 +589,10 @@ func setCACert(client *http.Client, cert *x509.Certificate) {
 		rootCAs = x509.NewCertPool()
 	}
 	rootCAs.AddCert(cert)
+	setRootCAs(client, rootCAs)
+}
+
+func setRootCAs(client *http.Client, rootCAs *x509.CertPool) {
 	if client.Transport == nil {
 		client.Transport = new(http.Transport)
 	}
@@ -2,6 +2,10 @@ package httpclient_test
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	corev2 "github.com/sensu/sensu-go/api/core/v2"
@@ -70,3 +74,143 @@ func TestClientDelete(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestClientList(t *testing.T) {
+	pages := map[string][]*corev2.CheckConfig{
+		"": {
+			corev2.FixtureCheckConfig("disk"),
+			corev2.FixtureCheckConfig("cpu"),
+		},
+		"page2": {
+			corev2.FixtureCheckConfig("memory"),
+		},
+	}
+	listServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got, want := req.URL.Path, "/api/core/v2/namespaces/default/checks"; got != want {
+			t.Errorf("bad path: got %q, want %q", got, want)
+		}
+		query := req.URL.Query()
+		if got, want := query.Get("limit"), "2"; got != want {
+			t.Errorf("bad limit: got %q, want %q", got, want)
+		}
+		if got, want := query.Get("labelSelector"), "region == us-west-1"; got != want {
+			t.Errorf("bad label selector: got %q, want %q", got, want)
+		}
+		if got, want := query.Get("fieldSelector"), "check.publish == true"; got != want {
+			t.Errorf("bad field selector: got %q, want %q", got, want)
+		}
+		token := query.Get("continue")
+		if token == "" {
+			w.Header().Set("Sensu-Continue", "page2")
+		}
+		_ = json.NewEncoder(w).Encode(pages[token])
+	}))
+	defer listServer.Close()
+
+	config := httpclient.CoreClientConfig{
+		URL:    listServer.URL,
+		APIKey: "use transport layer security",
+		CACert: listServer.Certificate(),
+	}
+	cl := httpclient.NewCoreClient(config)
+	req, err := httpclient.NewListRequest("core/v2", "CheckConfig", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := httpclient.ListOptions{
+		Limit:         2,
+		LabelSelector: "region == us-west-1",
+		FieldSelector: "check.publish == true",
+	}
+	resources, err := cl.ListResources(context.Background(), req, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, resource := range resources {
+		check, ok := resource.(*corev2.CheckConfig)
+		if !ok {
+			t.Fatalf("bad resource type: %T", resource)
+		}
+		names = append(names, check.Name)
+	}
+	if got, want := len(names), 3; got != want {
+		t.Fatalf("bad number of resources: got %d, want %d", got, want)
+	}
+	for i, want := range []string{"disk", "cpu", "memory"} {
+		if names[i] != want {
+			t.Errorf("bad resource %d: got %q, want %q", i, names[i], want)
+		}
+	}
+}
+
+func TestClientListWithoutTypeMeta(t *testing.T) {
+	listServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.RawQuery != "" {
+			t.Errorf("unexpected query: %q", req.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode([]*corev2.CheckConfig{corev2.FixtureCheckConfig("disk")})
+	}))
+	defer listServer.Close()
+
+	config := httpclient.CoreClientConfig{
+		URL:    listServer.URL,
+		APIKey: "use transport layer security",
+		CACert: listServer.Certificate(),
+	}
+	cl := httpclient.NewCoreClient(config)
+	check := &corev2.CheckConfig{ObjectMeta: corev2.ObjectMeta{Namespace: "default"}}
+	req := httpclient.ResourceRequest{Resource: check}
+	resources, err := cl.ListResources(context.Background(), req, httpclient.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 1 {
+		t.Fatalf("bad number of resources: %d", len(resources))
+	}
+	if _, ok := resources[0].(*corev2.CheckConfig); !ok {
+		t.Fatalf("bad resource type: %T", resources[0])
+	}
+}
+
+func TestClientListError(t *testing.T) {
+	listServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer listServer.Close()
+
+	config := httpclient.CoreClientConfig{
+		URL:    listServer.URL,
+		APIKey: "use transport layer security",
+		CACert: listServer.Certificate(),
+	}
+	cl := httpclient.NewCoreClient(config)
+	req, err := httpclient.NewListRequest("core/v2", "CheckConfig", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cl.ListResources(context.Background(), req, httpclient.ListOptions{})
+	httpErr, ok := err.(httpclient.HTTPError)
+	if !ok {
+		t.Fatalf("expected an HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("bad status code: %d", httpErr.StatusCode)
+	}
+}
+
+func TestClientCACertPool(t *testing.T) {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	config := httpclient.CoreClientConfig{
+		URL:        server.URL,
+		APIKey:     "use transport layer security",
+		CACertPool: pool,
+	}
+	cl := httpclient.NewCoreClient(config)
+	req := httpclient.NewEventRequest("default", "server", "network")
+	event := new(corev2.Event)
+	if _, err := cl.GetResource(context.Background(), req, event); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"errors"
+	"net"
+	"net/http"
+)
+
+var (
+	// ErrNotFound matches the errors of requests for resources that don't
+	// exist, with errors.Is.
+	ErrNotFound = errors.New("resource not found")
+
+	// ErrForbidden matches the errors of requests for resources the client
+	// is not allowed to access, with errors.Is.
+	ErrForbidden = errors.New("access to resource forbidden")
+)
+
+// apiError is the body of the error responses of the Sensu API.
+type apiError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// statusCode returns the status code of the HTTPError wrapped by err, or 0.
+func statusCode(err error) int {
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound returns true if err wraps an HTTPError for a missing resource.
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized returns true if err wraps an HTTPError for a request with
+// invalid credentials.
+func IsUnauthorized(err error) bool {
+	return statusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden returns true if err wraps an HTTPError for a request the
+// credentials don't allow.
+func IsForbidden(err error) bool {
+	return statusCode(err) == http.StatusForbidden
+}
+
+// IsConflict returns true if err wraps an HTTPError for a request conflicting
+// with the current state of a resource, such as creating an existing one.
+func IsConflict(err error) bool {
+	return statusCode(err) == http.StatusConflict
+}
+
+// IsRetryable returns true if the request failed with a connection error, or
+// with an HTTPError whose status code is in DefaultRetryableStatusCodes or is
+// 429 Too Many Requests. The request may succeed if sent again later.
+func IsRetryable(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	code := statusCode(err)
+	if code == http.StatusTooManyRequests {
+		return true
+	}
+	for _, retryable := range DefaultRetryableStatusCodes {
+		if code == retryable {
+			return true
+		}
+	}
+	return false
+}
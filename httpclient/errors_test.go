@@ -0,0 +1,117 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+func TestHTTPErrorAPIError(t *testing.T) {
+	errServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message":"resource already exists","code":5}`))
+	}))
+	defer errServer.Close()
+
+	config := httpclient.CoreClientConfig{
+		URL:    errServer.URL,
+		APIKey: "use transport layer security",
+		CACert: errServer.Certificate(),
+	}
+	cl := httpclient.NewCoreClient(config)
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	_, err := cl.PostResource(context.Background(), req)
+	var httpErr httpclient.HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected an HTTPError, got %v", err)
+	}
+	if httpErr.Message != "resource already exists" || httpErr.Code != 5 {
+		t.Fatalf("bad API error: %+v", httpErr)
+	}
+	if got, want := err.Error(), "error 409: resource already exists"; got != want {
+		t.Fatalf("bad error message: got %q, want %q", got, want)
+	}
+	if !httpclient.IsConflict(err) {
+		t.Fatal("expected a conflict")
+	}
+}
+
+func TestHTTPErrorPlainBody(t *testing.T) {
+	err := httpclient.HTTPError{StatusCode: http.StatusBadGateway, Body: "<html>bad gateway</html>"}
+	if got, want := err.Error(), "error 502: <html>bad gateway</html>"; got != want {
+		t.Fatalf("bad error message: got %q, want %q", got, want)
+	}
+}
+
+func TestHTTPErrorKinds(t *testing.T) {
+	tests := []struct {
+		status       int
+		notFound     bool
+		unauthorized bool
+		forbidden    bool
+		conflict     bool
+		retryable    bool
+	}{
+		{status: http.StatusNotFound, notFound: true},
+		{status: http.StatusUnauthorized, unauthorized: true},
+		{status: http.StatusForbidden, forbidden: true},
+		{status: http.StatusConflict, conflict: true},
+		{status: http.StatusTooManyRequests, retryable: true},
+		{status: http.StatusBadGateway, retryable: true},
+		{status: http.StatusServiceUnavailable, retryable: true},
+		{status: http.StatusGatewayTimeout, retryable: true},
+		{status: http.StatusInternalServerError},
+		{status: http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			// The kinds are found through wrapped errors
+			err := fmt.Errorf("error executing handler: %w", httpclient.HTTPError{StatusCode: test.status})
+			if got := httpclient.IsNotFound(err); got != test.notFound {
+				t.Errorf("IsNotFound: got %v, want %v", got, test.notFound)
+			}
+			if got := httpclient.IsUnauthorized(err); got != test.unauthorized {
+				t.Errorf("IsUnauthorized: got %v, want %v", got, test.unauthorized)
+			}
+			if got := httpclient.IsForbidden(err); got != test.forbidden {
+				t.Errorf("IsForbidden: got %v, want %v", got, test.forbidden)
+			}
+			if got := httpclient.IsConflict(err); got != test.conflict {
+				t.Errorf("IsConflict: got %v, want %v", got, test.conflict)
+			}
+			if got := httpclient.IsRetryable(err); got != test.retryable {
+				t.Errorf("IsRetryable: got %v, want %v", got, test.retryable)
+			}
+		})
+	}
+}
+
+func TestIsRetryableConnectionError(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	url := closed.URL
+	closed.Close()
+
+	config := httpclient.CoreClientConfig{
+		URL:    url,
+		APIKey: "use transport layer security",
+	}
+	cl := httpclient.NewCoreClient(config)
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	_, err := cl.DeleteResource(context.Background(), req)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !httpclient.IsRetryable(err) {
+		t.Fatalf("connection error is not retryable: %v", err)
+	}
+	if httpclient.IsRetryable(errors.New("invalid event")) {
+		t.Fatal("plain error is retryable")
+	}
+}
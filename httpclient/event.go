@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"context"
+	"time"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+)
+
+// NewEvent creates an event ready to be published, with the result of a check
+// on an entity. When published to the backend, the entity is created as a
+// proxy entity if it does not exist.
+func NewEvent(namespace, entity, check string, status uint32, output string) *corev2.Event {
+	now := time.Now().Unix()
+	return &corev2.Event{
+		ObjectMeta: corev2.ObjectMeta{
+			Namespace: namespace,
+		},
+		Timestamp: now,
+		Entity: &corev2.Entity{
+			ObjectMeta: corev2.ObjectMeta{
+				Namespace: namespace,
+				Name:      entity,
+			},
+			EntityClass: corev2.EntityProxyClass,
+		},
+		Check: &corev2.Check{
+			ObjectMeta: corev2.ObjectMeta{
+				Namespace: namespace,
+				Name:      check,
+			},
+			Status:   status,
+			Output:   output,
+			Executed: now,
+			Issued:   now,
+		},
+	}
+}
+
+// PublishEvent validates the event and sends it to the backend events API,
+// which processes it like the events of agents. Use NewEvent to create the
+// event.
+//
+// If the backend rejects the event with a 4xx or 5xx status, an HTTPError is
+// returned with the status code and the first 64KB of the response body.
+func (c *CoreClient) PublishEvent(ctx context.Context, event *corev2.Event) error {
+	if err := prepareEvent(event); err != nil {
+		return err
+	}
+	_, err := c.PutResource(ctx, ResourceRequest{Resource: event})
+	return err
+}
+
+// prepareEvent validates the event, and fills in its timestamp if missing.
+func prepareEvent(event *corev2.Event) error {
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().Unix()
+	}
+	return event.Validate()
+}
@@ -0,0 +1,54 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+func TestClientPublishEvent(t *testing.T) {
+	var published corev2.Event
+	eventServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPut {
+			t.Errorf("bad method: %s", req.Method)
+		}
+		if got, want := req.URL.Path, "/api/core/v2/namespaces/default/events/server1/handler-failure"; got != want {
+			t.Errorf("bad path: got %q, want %q", got, want)
+		}
+		if err := json.NewDecoder(req.Body).Decode(&published); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer eventServer.Close()
+
+	config := httpclient.CoreClientConfig{
+		URL:    eventServer.URL,
+		APIKey: "use transport layer security",
+		CACert: eventServer.Certificate(),
+	}
+	cl := httpclient.NewCoreClient(config)
+	event := httpclient.NewEvent("default", "server1", "handler-failure", 2, "failed to deliver")
+	if err := cl.PublishEvent(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if published.Check == nil || published.Check.Status != 2 || published.Check.Output != "failed to deliver" {
+		t.Fatalf("bad published check: %+v", published.Check)
+	}
+	if published.Entity == nil || published.Entity.EntityClass != corev2.EntityProxyClass {
+		t.Fatalf("bad published entity: %+v", published.Entity)
+	}
+	if published.Timestamp == 0 {
+		t.Fatal("0 timestamp")
+	}
+
+	invalid := httpclient.NewEvent("default", "", "handler-failure", 2, "failed to deliver")
+	if err := cl.PublishEvent(context.Background(), invalid); err == nil {
+		t.Fatal("expected an error for an event without entity name")
+	}
+}
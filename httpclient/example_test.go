@@ -84,6 +84,38 @@ func ExampleCoreClient_DeleteResource() {
 	fmt.Println("HTTP Response", resp.Status)
 }
 
+func ExampleCoreClient_ListResources() {
+	config := httpclient.CoreClientConfig{
+		URL:    "https://sensu-backend:8080",
+		APIKey: "use transport layer security",
+	}
+	client := httpclient.NewCoreClient(config)
+	req, err := httpclient.NewListRequest("core/v2", "CheckConfig", "default")
+	if err != nil {
+		panic(err)
+	}
+	options := httpclient.ListOptions{
+		Limit:         100,
+		LabelSelector: "region == us-west-1",
+	}
+	checks, err := client.ListResources(context.Background(), req, options)
+	if err != nil {
+		panic(err)
+	}
+	for _, check := range checks {
+		fmt.Println(check.GetObjectMeta().Name)
+	}
+}
+
+func ExampleNewListRequest() {
+	req, err := httpclient.NewListRequest("core/v2", "CheckConfig", "default")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(req.Resource.URIPath())
+	// Output: /api/core/v2/namespaces/default/checks
+}
+
 func ExampleNewResourceRequest() {
 	req, err := httpclient.NewResourceRequest("core/v2", "CheckConfig", "default", "disk")
 	if err != nil {
@@ -0,0 +1,139 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// backendURLs returns the URLs of the backends of the config.
+func (c *CoreClientConfig) backendURLs() []string {
+	if len(c.URLs) > 0 {
+		return c.URLs
+	}
+	return []string{c.URL}
+}
+
+// Backend returns the URL of the backend the client sends requests to.
+func (c *CoreClient) Backend() string {
+	backends := c.Config.backendURLs()
+	c.backendMu.Lock()
+	defer c.backendMu.Unlock()
+	return backends[c.backend%len(backends)]
+}
+
+// failover sends the request with the send function. When the backend the
+// request is sent to fails, the request is sent again to the next healthy
+// backend, until all the backends have been tried.
+func (c *CoreClient) failover(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	backends := c.Config.backendURLs()
+	for tries := 1; ; tries++ {
+		resp, err := send(req)
+		if tries >= len(backends) || req.Context().Err() != nil || !backendFailed(resp, err) {
+			return resp, err
+		}
+		failed := backendOf(req, backends)
+		if failed < 0 {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+		next := c.nextBackend(req.Context(), failed)
+		if req, err = moveRequest(req, backends[failed], backends[next]); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// toBackend returns the request, or a copy of it sent to the current backend
+// if the client failed over from the backend of the request.
+func (c *CoreClient) toBackend(req *http.Request) (*http.Request, error) {
+	backends := c.Config.backendURLs()
+	from, current := backendOf(req, backends), c.Backend()
+	if from < 0 || backends[from] == current {
+		return req, nil
+	}
+	return moveRequest(req, backends[from], current)
+}
+
+// moveRequest returns a copy of the request sent to the backend to instead of
+// the backend from.
+func moveRequest(req *http.Request, from, to string) (*http.Request, error) {
+	moved, err := cloneRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if moved.URL, err = url.Parse(to + strings.TrimPrefix(req.URL.String(), from)); err != nil {
+		return nil, err
+	}
+	moved.Host = ""
+	return moved, nil
+}
+
+// backendFailed returns true if the outcome of a request shows the backend
+// is unavailable.
+func backendFailed(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= 500
+}
+
+// backendOf returns the index of the backend the request is sent to, or -1.
+func backendOf(req *http.Request, backends []string) int {
+	location := req.URL.String()
+	for i, backend := range backends {
+		if strings.HasPrefix(location, backend) {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextBackend switches from the failed backend to the next healthy one, and
+// returns its index. The next backend is used if none is healthy. If another
+// request already switched from the failed backend, the backend it switched
+// to is returned.
+func (c *CoreClient) nextBackend(ctx context.Context, failed int) int {
+	backends := c.Config.backendURLs()
+	c.backendMu.Lock()
+	current := c.backend
+	c.backendMu.Unlock()
+	if current != failed {
+		return current
+	}
+
+	next := (failed + 1) % len(backends)
+	for i := 1; i < len(backends); i++ {
+		candidate := (failed + i) % len(backends)
+		if c.healthy(ctx, backends[candidate]) {
+			next = candidate
+			break
+		}
+	}
+
+	c.backendMu.Lock()
+	defer c.backendMu.Unlock()
+	if c.backend == failed {
+		c.backend = next
+	}
+	return c.backend
+}
+
+// healthy returns true if the /health endpoint of the backend reports it as
+// healthy.
+func (c *CoreClient) healthy(ctx context.Context, backend string) bool {
+	req, err := newHTTPRequest(ctx, http.MethodGet, backend+"/health", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := c.roundTrip(req)
+	if err != nil {
+		return false
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
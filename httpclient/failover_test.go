@@ -0,0 +1,142 @@
+package httpclient_test
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+// clusterBackend is a fake backend that counts the resource requests it
+// receives, and answers them with status, after answering the first failures
+// of them with 503.
+type clusterBackend struct {
+	status       int
+	healthStatus int
+	failures     int32
+	requests     int32
+}
+
+func (b *clusterBackend) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/health" {
+		w.WriteHeader(b.healthStatus)
+		return
+	}
+	if atomic.AddInt32(&b.requests, 1) <= b.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(b.status)
+}
+
+func newClusterClient(t *testing.T, servers ...*httptest.Server) *httpclient.CoreClient {
+	t.Helper()
+	config := httpclient.CoreClientConfig{
+		APIKey: "use transport layer security",
+	}
+	cl := httpclient.NewCoreClient(config)
+	roots := x509.NewCertPool()
+	for _, server := range servers {
+		cl.Config.URLs = append(cl.Config.URLs, server.URL)
+		roots.AddCert(server.Certificate())
+	}
+	transport := servers[0].Client().Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig.RootCAs = roots
+	cl.HTTPClient.Transport = transport
+	return cl
+}
+
+func TestClientFailover(t *testing.T) {
+	down := httptest.NewTLSServer(http.NotFoundHandler())
+	unhealthyBackend := &clusterBackend{status: http.StatusOK, healthStatus: http.StatusServiceUnavailable}
+	unhealthy := httptest.NewTLSServer(unhealthyBackend)
+	defer unhealthy.Close()
+	healthyBackend := &clusterBackend{status: http.StatusOK, healthStatus: http.StatusOK}
+	healthy := httptest.NewTLSServer(healthyBackend)
+	defer healthy.Close()
+
+	cl := newClusterClient(t, down, unhealthy, healthy)
+	down.Close()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	for i := 0; i < 2; i++ {
+		if _, err := cl.PutResource(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := cl.Backend(), healthy.URL; got != want {
+		t.Errorf("bad backend: got %q, want %q", got, want)
+	}
+	if got := atomic.LoadInt32(&unhealthyBackend.requests); got != 0 {
+		t.Errorf("unhealthy backend received %d requests", got)
+	}
+	if got := atomic.LoadInt32(&healthyBackend.requests); got != 2 {
+		t.Errorf("expected 2 requests on the healthy backend, got %d", got)
+	}
+}
+
+func TestClientFailoverServerError(t *testing.T) {
+	failingBackend := &clusterBackend{status: http.StatusBadGateway, healthStatus: http.StatusBadGateway}
+	failing := httptest.NewTLSServer(failingBackend)
+	defer failing.Close()
+	healthyBackend := &clusterBackend{status: http.StatusNoContent, healthStatus: http.StatusOK}
+	healthy := httptest.NewTLSServer(healthyBackend)
+	defer healthy.Close()
+
+	cl := newClusterClient(t, failing, healthy)
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	if _, err := cl.DeleteResource(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cl.Backend(), healthy.URL; got != want {
+		t.Errorf("bad backend: got %q, want %q", got, want)
+	}
+}
+
+func TestClientFailoverAllBackendsDown(t *testing.T) {
+	var servers []*httptest.Server
+	for i := 0; i < 3; i++ {
+		server := httptest.NewTLSServer(&clusterBackend{status: http.StatusServiceUnavailable, healthStatus: http.StatusServiceUnavailable})
+		defer server.Close()
+		servers = append(servers, server)
+	}
+	cl := newClusterClient(t, servers...)
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	_, err := cl.DeleteResource(context.Background(), req)
+	if httpErr, ok := err.(httpclient.HTTPError); !ok || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 HTTPError, got %v", err)
+	}
+}
+
+func TestClientFailoverRetry(t *testing.T) {
+	downBackend := &clusterBackend{status: http.StatusServiceUnavailable, healthStatus: http.StatusServiceUnavailable}
+	down := httptest.NewTLSServer(downBackend)
+	defer down.Close()
+	var servers []*httptest.Server
+	for i := 0; i < 2; i++ {
+		server := httptest.NewTLSServer(&clusterBackend{status: http.StatusNoContent, healthStatus: http.StatusOK, failures: 1})
+		defer server.Close()
+		servers = append(servers, server)
+	}
+
+	// every backend fails the first attempt, the retry is sent to the last
+	// backend the client failed over to
+	cl := newClusterClient(t, down, servers[0], servers[1])
+	cl.Config.Retry = httpclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	if _, err := cl.DeleteResource(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cl.Backend(), servers[1].URL; got != want {
+		t.Errorf("bad backend: got %q, want %q", got, want)
+	}
+	if got := atomic.LoadInt32(&downBackend.requests); got != 1 {
+		t.Errorf("expected 1 request on the backend that is down, got %d", got)
+	}
+}
@@ -0,0 +1,67 @@
+package httpclient
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RoundTripperFunc is an adapter to use a function as an http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the sending of requests by a CoreClient. A middleware can
+// inspect or modify the request before passing it to next, and inspect the
+// response.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// roundTrip sends the request with the HTTP client, through the middleware of
+// the config.
+func (c *CoreClient) roundTrip(req *http.Request) (*http.Response, error) {
+	var rt http.RoundTripper = RoundTripperFunc(c.HTTPClient.Do)
+	if c.Config.Debug {
+		rt = DebugLogger(log.Printf)(rt)
+	}
+	for i := len(c.Config.Middleware) - 1; i >= 0; i-- {
+		rt = c.Config.Middleware[i](rt)
+	}
+	return rt.RoundTrip(req)
+}
+
+// DebugLogger returns a middleware logging the method, URL and Authorization
+// scheme of every request, along with the status and latency of its response.
+// The credentials of the Authorization header are redacted.
+func DebugLogger(logf func(format string, args ...interface{})) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			latency := time.Since(start).Round(time.Microsecond)
+			auth := redactAuthorization(req.Header.Get("Authorization"))
+			if err != nil {
+				logf("%s %s (authorization: %s): error after %s: %s", req.Method, req.URL, auth, latency, err)
+				return resp, err
+			}
+			logf("%s %s (authorization: %s): %s in %s", req.Method, req.URL, auth, resp.Status, latency)
+			return resp, err
+		})
+	}
+}
+
+// redactAuthorization keeps the scheme of the Authorization header value, and
+// hides the credentials.
+func redactAuthorization(value string) string {
+	if value == "" {
+		return "none"
+	}
+	fields := strings.SplitN(value, " ", 2)
+	if len(fields) < 2 {
+		return "[redacted]"
+	}
+	return fields[0] + " [redacted]"
+}
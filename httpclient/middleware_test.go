@@ -0,0 +1,91 @@
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+func TestClientMiddleware(t *testing.T) {
+	var calls []string
+	tracer := func(name string) httpclient.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				req.Header.Set("X-Trace-"+name, "1")
+				resp, err := next.RoundTrip(req)
+				calls = append(calls, name+" after")
+				return resp, err
+			})
+		}
+	}
+	config := httpclient.CoreClientConfig{
+		URL:        server.URL,
+		APIKey:     "use transport layer security",
+		CACert:     server.Certificate(),
+		Middleware: []httpclient.Middleware{tracer("outer"), tracer("inner")},
+	}
+	cl := httpclient.NewCoreClient(config)
+	req := httpclient.NewEventRequest("default", "server", "network")
+	resp, err := cl.GetResource(context.Background(), req, new(corev2.Event))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Request.Header.Get("X-Trace-outer") != "1" || resp.Request.Header.Get("X-Trace-inner") != "1" {
+		t.Fatalf("middleware headers not sent: %v", resp.Request.Header)
+	}
+	want := "outer before,inner before,inner after,outer after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Fatalf("bad middleware calls: got %q, want %q", got, want)
+	}
+}
+
+func TestDebugLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+	config := httpclient.CoreClientConfig{
+		URL:        server.URL,
+		APIKey:     "use transport layer security",
+		CACert:     server.Certificate(),
+		Middleware: []httpclient.Middleware{httpclient.DebugLogger(logger.Printf)},
+	}
+	cl := httpclient.NewCoreClient(config)
+	req := httpclient.NewEventRequest("default", "server", "network")
+	if _, err := cl.GetResource(context.Background(), req, new(corev2.Event)); err != nil {
+		t.Fatal(err)
+	}
+	line := buf.String()
+	wantPrefix := fmt.Sprintf("GET %s/api/core/v2/namespaces/default/events/server/network (authorization: Key [redacted]): 200 OK in ", server.URL)
+	if !strings.HasPrefix(line, wantPrefix) {
+		t.Fatalf("bad debug log: got %q, want prefix %q", line, wantPrefix)
+	}
+	if strings.Contains(line, "transport layer security") {
+		t.Fatal("API key not redacted")
+	}
+}
+
+func TestDebugLoggerError(t *testing.T) {
+	var lines []string
+	logf := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	config := httpclient.CoreClientConfig{
+		URL:        "http://127.0.0.1:0",
+		Middleware: []httpclient.Middleware{httpclient.DebugLogger(logf)},
+	}
+	cl := httpclient.NewCoreClient(config)
+	req := httpclient.NewEventRequest("default", "server", "network")
+	if _, err := cl.DeleteResource(context.Background(), req); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(lines) != 1 || !strings.Contains(lines[0], "(authorization: Key [redacted]): error after ") {
+		t.Fatalf("bad debug log: %q", lines)
+	}
+}
@@ -0,0 +1,163 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sensu/sensu-plugin-sdk/sensu"
+)
+
+// OutboundConfig contains the configuration of an OutboundClient, for the
+// third-party services handlers send events to, such as webhooks. Use
+// OutboundOptions to fill it from plugin options.
+type OutboundConfig struct {
+	// URL is the URL PostJSON sends requests to.
+	URL string
+
+	// Proxy is the URL of the proxy requests go through. When empty, the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+	Proxy string
+
+	// CACertificate is the path of a PEM or DER encoded CA certificate, or
+	// of a PEM bundle, trusted in addition to the system certificates.
+	CACertificate string
+
+	// InsecureSkipVerify disables TLS hostname verification. This should not
+	// be used outside of testing!
+	InsecureSkipVerify bool
+
+	// Timeout is the timeout of each attempt of a request, in seconds. There
+	// is no timeout when it is 0.
+	Timeout int
+
+	// Headers are added to every request, for example to authenticate.
+	Headers map[string]string
+
+	// Retries is the number of times failed requests are retried, when the
+	// MaxAttempts of Retry is not set.
+	Retries int
+
+	// Retry is the policy for retrying failed requests.
+	Retry RetryPolicy
+}
+
+// OutboundOptions adds the following flags to a plugin, where prefix is a
+// name for the service such as "webhook":
+//
+//	--<prefix>-url
+//	--<prefix>-proxy
+//	--<prefix>-ca-cert
+//	--<prefix>-insecure-skip-verify
+//	--<prefix>-timeout
+//	--<prefix>-header
+//	--<prefix>-retries
+//
+// The environment variables of the options are the uppercased flag names,
+// for example WEBHOOK_URL.
+func OutboundOptions(prefix string, config *OutboundConfig) []*sensu.PluginConfigOption {
+	option := func(name string, value interface{}, def interface{}, usage string) *sensu.PluginConfigOption {
+		argument := prefix + "-" + name
+		return &sensu.PluginConfigOption{
+			Value:    value,
+			Path:     argument,
+			Env:      strings.ToUpper(strings.Replace(argument, "-", "_", -1)),
+			Argument: argument,
+			Default:  def,
+			Usage:    usage,
+		}
+	}
+	headers := option("header", &config.Headers, nil, fmt.Sprintf("Headers of the %s requests, as name=value pairs", prefix))
+	headers.Secret = true
+	return []*sensu.PluginConfigOption{
+		option("url", &config.URL, nil, fmt.Sprintf("URL of the %s", prefix)),
+		option("proxy", &config.Proxy, nil, fmt.Sprintf("URL of the proxy for the %s requests", prefix)),
+		option("ca-cert", &config.CACertificate, nil, fmt.Sprintf("CA certificate of the %s, PEM or DER encoded", prefix)),
+		option("insecure-skip-verify", &config.InsecureSkipVerify, nil, fmt.Sprintf("Disables TLS hostname verification for the %s", prefix)),
+		option("timeout", &config.Timeout, 10, fmt.Sprintf("Timeout of the %s requests, in seconds", prefix)),
+		headers,
+		option("retries", &config.Retries, 0, fmt.Sprintf("Number of retries of failed %s requests", prefix)),
+	}
+}
+
+// OutboundClient is an HTTP client for third-party services, with the TLS,
+// proxy, timeout, headers and retries of its OutboundConfig.
+type OutboundClient struct {
+	HTTPClient http.Client
+	Config     OutboundConfig
+}
+
+// NewOutboundClient creates a new OutboundClient that uses the supplied
+// OutboundConfig. It returns an error if the CA certificate can't be loaded,
+// or if the proxy URL is invalid.
+func NewOutboundClient(config OutboundConfig) (*OutboundClient, error) {
+	if config.Retry.MaxAttempts == 0 {
+		config.Retry.MaxAttempts = config.Retries + 1
+	}
+	client := &OutboundClient{
+		Config: config,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	client.HTTPClient.Transport = transport
+	client.HTTPClient.Timeout = time.Duration(config.Timeout) * time.Second
+
+	if config.Proxy != "" {
+		proxy, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %s", config.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if config.CACertificate != "" {
+		security := sensu.SecurityConfig{CACertificate: config.CACertificate}
+		pool, err := security.GetCACertPool()
+		if err != nil {
+			return nil, err
+		}
+		setRootCAs(&client.HTTPClient, pool)
+	}
+	if config.InsecureSkipVerify {
+		setInsecureSkipVerify(&client.HTTPClient)
+	}
+	return client, nil
+}
+
+// Do sends the request with the headers of the config, retrying it according
+// to the retry policy of the config. Like http.Client.Do, the caller must close
+// the body of the response.
+func (c *OutboundClient) Do(req *http.Request) (*http.Response, error) {
+	for name, value := range c.Config.Headers {
+		req.Header.Set(name, value)
+	}
+	return c.Config.Retry.do(req, c.HTTPClient.Do)
+}
+
+// PostJSON posts v, encoded as JSON, to the URL of the config.
+//
+// If the server responds with a 4xx or 5xx status, after the retries of the
+// retry policy, an HTTPError is returned with the status code and the first
+// 64KB of the response body. Whenever a response is received, it is returned
+// with its body closed, along with any HTTPError.
+func (c *OutboundClient) PostJSON(ctx context.Context, v interface{}) (*http.Response, error) {
+	if c.Config.URL == "" {
+		return nil, fmt.Errorf("no URL configured")
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newHTTPRequest(ctx, http.MethodPost, c.Config.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return resp, validateResponse(resp)
+}
@@ -0,0 +1,155 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+	"github.com/sensu/sensu-plugin-sdk/sensu"
+)
+
+func TestOutboundOptions(t *testing.T) {
+	var config httpclient.OutboundConfig
+	pluginConfig := &sensu.PluginConfig{Name: "webhook-handler", Short: "webhook handler"}
+	check := sensu.NewGoCheck(pluginConfig, httpclient.OutboundOptions("webhook", &config),
+		func(*types.Event) (int, error) {
+			return sensu.CheckStateOK, nil
+		},
+		func(*types.Event) (int, error) {
+			return sensu.CheckStateOK, nil
+		}, false)
+	env := sensu.Environment{
+		Args: []string{
+			"--webhook-url", "https://hooks.example.com/sensu",
+			"--webhook-header", "X-Token=secret",
+			"--webhook-insecure-skip-verify",
+		},
+		Env: map[string]string{
+			"WEBHOOK_RETRIES": "2",
+			"WEBHOOK_PROXY":   "http://proxy.example.com:3128",
+		},
+	}
+	if status := check.ExecuteWithEnvironment(env); status != sensu.CheckStateOK {
+		t.Fatalf("bad status: %d", status)
+	}
+	if config.URL != "https://hooks.example.com/sensu" || config.Proxy != "http://proxy.example.com:3128" {
+		t.Errorf("bad URLs: %+v", config)
+	}
+	if config.Headers["X-Token"] != "secret" || !config.InsecureSkipVerify {
+		t.Errorf("bad headers or TLS: %+v", config)
+	}
+	if config.Timeout != 10 || config.Retries != 2 {
+		t.Errorf("bad timeout or retries: %+v", config)
+	}
+}
+
+func TestOutboundClientPostJSON(t *testing.T) {
+	var attempts int32
+	webhook := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Token") != "secret" {
+			t.Errorf("missing header: %v", req.Header)
+		}
+		if req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("bad content type: %s", req.Header.Get("Content-Type"))
+		}
+		var body map[string]string
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body["text"] != "disk full" {
+			t.Errorf("bad body: %v (%v)", body, err)
+		}
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer webhook.Close()
+
+	caFile, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(caFile.Name())
+	if err := pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: webhook.Certificate().Raw}); err != nil {
+		t.Fatal(err)
+	}
+	caFile.Close()
+
+	config := httpclient.OutboundConfig{
+		URL:           webhook.URL,
+		CACertificate: caFile.Name(),
+		Timeout:       5,
+		Headers:       map[string]string{"X-Token": "secret"},
+		Retries:       1,
+		Retry:         httpclient.RetryPolicy{BaseDelay: time.Millisecond},
+	}
+	cl, err := httpclient.NewOutboundClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := cl.PostJSON(context.Background(), map[string]string{"text": "disk full"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("bad status: %d", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestOutboundClientProxy(t *testing.T) {
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		proxied <- req.RequestURI
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer proxy.Close()
+
+	config := httpclient.OutboundConfig{
+		URL:   "http://hooks.example.com/sensu",
+		Proxy: proxy.URL,
+	}
+	cl, err := httpclient.NewOutboundClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cl.PostJSON(context.Background(), map[string]string{"text": "disk full"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-proxied; got != "http://hooks.example.com/sensu" {
+		t.Fatalf("bad proxied request: %s", got)
+	}
+}
+
+func TestOutboundClientErrors(t *testing.T) {
+	if _, err := httpclient.NewOutboundClient(httpclient.OutboundConfig{CACertificate: "/nonexistent/ca.crt"}); err == nil {
+		t.Error("expected an error for a missing CA certificate")
+	}
+	if _, err := httpclient.NewOutboundClient(httpclient.OutboundConfig{Proxy: "://proxy"}); err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+		t.Errorf("expected an invalid proxy error, got %v", err)
+	}
+
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+	}))
+	defer webhook.Close()
+	cl, err := httpclient.NewOutboundClient(httpclient.OutboundConfig{URL: webhook.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cl.PostJSON(context.Background(), "payload")
+	if httpErr, ok := err.(httpclient.HTTPError); !ok || httpErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a 400 HTTPError, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-go/types"
+)
+
+// ResourceError is the error returned by the typed resource helpers of
+// CoreClient, such as GetEntity. It wraps the error of the request, usually an
+// HTTPError, so that errors.Is(err, ErrNotFound) tells missing resources apart.
+type ResourceError struct {
+	Request ResourceRequest
+	Err     error
+}
+
+func (r ResourceError) Error() string {
+	return fmt.Sprintf("%s: %s", r.Request, r.Err)
+}
+
+// Unwrap returns the error of the request.
+func (r ResourceError) Unwrap() error {
+	return r.Err
+}
+
+// MetadataPatch specifies changes to the labels and annotations of a
+// resource.
+type MetadataPatch struct {
+	// Labels and Annotations are added to the resource, replacing the
+	// values of existing keys.
+	Labels      map[string]string
+	Annotations map[string]string
+
+	// RemoveLabels and RemoveAnnotations are the keys removed from the
+	// resource.
+	RemoveLabels      []string
+	RemoveAnnotations []string
+}
+
+func (p MetadataPatch) apply(meta *corev2.ObjectMeta) {
+	meta.Labels = patchMap(meta.Labels, p.Labels, p.RemoveLabels)
+	meta.Annotations = patchMap(meta.Annotations, p.Annotations, p.RemoveAnnotations)
+}
+
+func patchMap(m, set map[string]string, remove []string) map[string]string {
+	if m == nil && len(set) > 0 {
+		m = make(map[string]string, len(set))
+	}
+	for k, v := range set {
+		m[k] = v
+	}
+	for _, k := range remove {
+		delete(m, k)
+	}
+	return m
+}
+
+// getTyped gets the core/v2 resource of the type with the given namespace and
+// name.
+func (c *CoreClient) getTyped(ctx context.Context, typeName, namespace, name string) (types.Resource, error) {
+	req, err := NewResourceRequest("core/v2", typeName, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.GetResource(ctx, req, req.Resource); err != nil {
+		return nil, ResourceError{Request: req, Err: err}
+	}
+	return req.Resource, nil
+}
+
+// listTyped lists the core/v2 resources of the type in the namespace.
+func (c *CoreClient) listTyped(ctx context.Context, typeName, namespace string, options ListOptions) ([]types.Resource, error) {
+	req, err := NewListRequest("core/v2", typeName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := c.ListResources(ctx, req, options)
+	if err != nil {
+		return nil, ResourceError{Request: req, Err: err}
+	}
+	return resources, nil
+}
+
+// putTyped creates or updates the resource.
+func (c *CoreClient) putTyped(ctx context.Context, typeName string, resource types.Resource) error {
+	meta := resource.GetObjectMeta()
+	req := ResourceRequest{
+		TypeMeta: corev2.TypeMeta{
+			APIVersion: "core/v2",
+			Type:       typeName,
+		},
+		ObjectMeta: corev2.ObjectMeta{
+			Namespace: meta.Namespace,
+			Name:      meta.Name,
+		},
+		Resource: resource,
+	}
+	if _, err := c.PutResource(ctx, req); err != nil {
+		return ResourceError{Request: req, Err: err}
+	}
+	return nil
+}
+
+// GetEntity gets the entity with the given namespace and name.
+func (c *CoreClient) GetEntity(ctx context.Context, namespace, name string) (*corev2.Entity, error) {
+	resource, err := c.getTyped(ctx, "Entity", namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return resource.(*corev2.Entity), nil
+}
+
+// ListEntities lists the entities in the namespace.
+func (c *CoreClient) ListEntities(ctx context.Context, namespace string, options ListOptions) ([]*corev2.Entity, error) {
+	resources, err := c.listTyped(ctx, "Entity", namespace, options)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*corev2.Entity, 0, len(resources))
+	for _, resource := range resources {
+		entities = append(entities, resource.(*corev2.Entity))
+	}
+	return entities, nil
+}
+
+// PutEntity creates or updates the entity.
+func (c *CoreClient) PutEntity(ctx context.Context, entity *corev2.Entity) error {
+	return c.putTyped(ctx, "Entity", entity)
+}
+
+// PatchEntity changes the labels and annotations of the entity with the given
+// namespace and name, and returns the updated entity. The entity is fetched and
+// updated, so concurrent changes to other fields of the entity can be lost.
+func (c *CoreClient) PatchEntity(ctx context.Context, namespace, name string, patch MetadataPatch) (*corev2.Entity, error) {
+	entity, err := c.GetEntity(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	patch.apply(&entity.ObjectMeta)
+	if err := c.PutEntity(ctx, entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// GetCheck gets the check configuration with the given namespace and name.
+func (c *CoreClient) GetCheck(ctx context.Context, namespace, name string) (*corev2.CheckConfig, error) {
+	resource, err := c.getTyped(ctx, "CheckConfig", namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return resource.(*corev2.CheckConfig), nil
+}
+
+// ListChecks lists the check configurations in the namespace.
+func (c *CoreClient) ListChecks(ctx context.Context, namespace string, options ListOptions) ([]*corev2.CheckConfig, error) {
+	resources, err := c.listTyped(ctx, "CheckConfig", namespace, options)
+	if err != nil {
+		return nil, err
+	}
+	checks := make([]*corev2.CheckConfig, 0, len(resources))
+	for _, resource := range resources {
+		checks = append(checks, resource.(*corev2.CheckConfig))
+	}
+	return checks, nil
+}
+
+// PutCheck creates or updates the check configuration.
+func (c *CoreClient) PutCheck(ctx context.Context, check *corev2.CheckConfig) error {
+	return c.putTyped(ctx, "CheckConfig", check)
+}
+
+// PatchCheck changes the labels and annotations of the check configuration
+// with the given namespace and name, and returns the updated check.
+func (c *CoreClient) PatchCheck(ctx context.Context, namespace, name string, patch MetadataPatch) (*corev2.CheckConfig, error) {
+	check, err := c.GetCheck(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	patch.apply(&check.ObjectMeta)
+	if err := c.PutCheck(ctx, check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// GetAsset gets the asset with the given namespace and name.
+func (c *CoreClient) GetAsset(ctx context.Context, namespace, name string) (*corev2.Asset, error) {
+	resource, err := c.getTyped(ctx, "Asset", namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return resource.(*corev2.Asset), nil
+}
+
+// ListAssets lists the assets in the namespace.
+func (c *CoreClient) ListAssets(ctx context.Context, namespace string, options ListOptions) ([]*corev2.Asset, error) {
+	resources, err := c.listTyped(ctx, "Asset", namespace, options)
+	if err != nil {
+		return nil, err
+	}
+	assets := make([]*corev2.Asset, 0, len(resources))
+	for _, resource := range resources {
+		assets = append(assets, resource.(*corev2.Asset))
+	}
+	return assets, nil
+}
+
+// PutAsset creates or updates the asset.
+func (c *CoreClient) PutAsset(ctx context.Context, asset *corev2.Asset) error {
+	return c.putTyped(ctx, "Asset", asset)
+}
@@ -0,0 +1,185 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+// resourceBackend is a fake backend storing resources by path. Requests in
+// the "secret" namespace are forbidden.
+type resourceBackend struct {
+	mu        sync.Mutex
+	resources map[string]string
+}
+
+func (b *resourceBackend) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if strings.Contains(req.URL.Path, "/namespaces/secret/") {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	switch req.Method {
+	case http.MethodGet:
+		if body, ok := b.resources[req.URL.Path]; ok {
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		var items []string
+		for path, body := range b.resources {
+			if strings.HasPrefix(path, req.URL.Path+"/") {
+				items = append(items, body)
+			}
+		}
+		if items == nil {
+			http.NotFound(w, req)
+			return
+		}
+		_, _ = w.Write([]byte("[" + strings.Join(items, ",") + "]"))
+	case http.MethodPut:
+		body, _ := ioutil.ReadAll(req.Body)
+		b.resources[req.URL.Path] = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func newResourceClient(t *testing.T) (*httpclient.CoreClient, func()) {
+	t.Helper()
+	server := httptest.NewTLSServer(&resourceBackend{resources: map[string]string{}})
+	config := httpclient.CoreClientConfig{
+		URL:    server.URL,
+		APIKey: "use transport layer security",
+		CACert: server.Certificate(),
+	}
+	return httpclient.NewCoreClient(config), server.Close
+}
+
+func TestClientEntities(t *testing.T) {
+	cl, cleanup := newResourceClient(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	entity := corev2.FixtureEntity("server1")
+	entity.Labels = map[string]string{"region": "us-west-1", "stale": "true"}
+	if err := cl.PutEntity(ctx, entity); err != nil {
+		t.Fatal(err)
+	}
+	got, err := cl.GetEntity(ctx, "default", "server1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "server1" || got.Labels["region"] != "us-west-1" {
+		t.Fatalf("bad entity: %+v", got)
+	}
+
+	patch := httpclient.MetadataPatch{
+		Labels:       map[string]string{"region": "us-east-1"},
+		Annotations:  map[string]string{"remediated": "true"},
+		RemoveLabels: []string{"stale"},
+	}
+	if _, err := cl.PatchEntity(ctx, "default", "server1", patch); err != nil {
+		t.Fatal(err)
+	}
+	got, err = cl.GetEntity(ctx, "default", "server1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Labels["region"] != "us-east-1" || got.Annotations["remediated"] != "true" {
+		t.Fatalf("patch not applied: %+v", got.ObjectMeta)
+	}
+	if _, ok := got.Labels["stale"]; ok {
+		t.Fatal("label not removed")
+	}
+
+	entities, err := cl.ListEntities(ctx, "default", httpclient.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 || entities[0].Name != "server1" {
+		t.Fatalf("bad entities: %v", entities)
+	}
+}
+
+func TestClientChecksAndAssets(t *testing.T) {
+	cl, cleanup := newResourceClient(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := cl.PutCheck(ctx, corev2.FixtureCheckConfig("check-disk")); err != nil {
+		t.Fatal(err)
+	}
+	check, err := cl.PatchCheck(ctx, "default", "check-disk", httpclient.MetadataPatch{Labels: map[string]string{"team": "storage"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Command != "command" || check.Labels["team"] != "storage" {
+		t.Fatalf("bad check: %+v", check)
+	}
+	checks, err := cl.ListChecks(ctx, "default", httpclient.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 1 || checks[0].Labels["team"] != "storage" {
+		t.Fatalf("bad checks: %v", checks)
+	}
+
+	asset := &corev2.Asset{
+		ObjectMeta: corev2.ObjectMeta{Namespace: "default", Name: "check-disk-usage"},
+		URL:        "https://assets.example.com/check-disk-usage.tar.gz",
+	}
+	if err := cl.PutAsset(ctx, asset); err != nil {
+		t.Fatal(err)
+	}
+	got, err := cl.GetAsset(ctx, "default", "check-disk-usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.URL != asset.URL {
+		t.Fatalf("bad asset URL: %s", got.URL)
+	}
+	assets, err := cl.ListAssets(ctx, "default", httpclient.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 1 {
+		t.Fatalf("bad assets: %v", assets)
+	}
+}
+
+func TestClientResourceErrors(t *testing.T) {
+	cl, cleanup := newResourceClient(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := cl.GetCheck(ctx, "default", "missing")
+	if !errors.Is(err, httpclient.ErrNotFound) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if errors.Is(err, httpclient.ErrForbidden) {
+		t.Fatal("not found error matches ErrForbidden")
+	}
+	var resourceErr httpclient.ResourceError
+	if !errors.As(err, &resourceErr) {
+		t.Fatalf("expected a ResourceError, got %T", err)
+	}
+	if resourceErr.Request.Name != "missing" || resourceErr.Request.Type != "CheckConfig" {
+		t.Fatalf("bad request: %s", resourceErr.Request)
+	}
+
+	_, err = cl.GetEntity(ctx, "secret", "server1")
+	if !errors.Is(err, httpclient.ErrForbidden) {
+		t.Fatalf("expected a forbidden error, got %v", err)
+	}
+	if _, err := cl.PatchEntity(ctx, "default", "missing", httpclient.MetadataPatch{}); !errors.Is(err, httpclient.ErrNotFound) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package httpclient
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRetryBaseDelay is the delay before the first retry when the
+	// BaseDelay of a RetryPolicy is not set.
+	DefaultRetryBaseDelay = 100 * time.Millisecond
+
+	// DefaultRetryMaxDelay is the maximum delay between retries when the
+	// MaxDelay of a RetryPolicy is not set.
+	DefaultRetryMaxDelay = 5 * time.Second
+)
+
+// DefaultRetryableStatusCodes are the response status codes retried when the
+// RetryableStatusCodes of a RetryPolicy are not set.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy specifies how a CoreClient retries failed requests. Requests
+// failing with a connection error or a retryable status code are sent again
+// after an exponentially increasing delay, until MaxAttempts is reached or
+// the next attempt would exceed the deadline of the request context.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a request, including
+	// the first one. Requests are not retried when MaxAttempts is lower than
+	// 2.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. The delay doubles with
+	// each retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between retries.
+	MaxDelay time.Duration
+
+	// Jitter is the fraction of the delay that is randomized, between 0 and
+	// 1. With a Jitter of 0.5, the client waits between half and all of the
+	// delay.
+	Jitter float64
+
+	// RetryableStatusCodes are the response status codes that are retried.
+	RetryableStatusCodes []int
+}
+
+// do sends the request with the send function, and sends it again while the
+// policy allows it.
+func (p RetryPolicy) do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		resp, err := send(req)
+		if attempt >= p.MaxAttempts || ctx.Err() != nil || !p.retryable(resp, err) {
+			return resp, err
+		}
+		delay := p.delay(attempt, resp)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			// The next attempt would not complete in time
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		if req, err = cloneRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable returns true if the outcome of an attempt warrants a retry.
+func (p RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	codes := p.RetryableStatusCodes
+	if codes == nil {
+		codes = DefaultRetryableStatusCodes
+	}
+	for _, code := range codes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait after the given attempt. The Retry-After
+// header of the response takes precedence over the backoff.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return delay
+		}
+	}
+	base, max := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	if max <= 0 {
+		max = DefaultRetryMaxDelay
+	}
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay -= time.Duration(float64(delay) * jitter * rand.Float64())
+	}
+	return delay
+}
+
+// retryAfter parses the value of a Retry-After header, either a number of
+// seconds or an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	delay := time.Until(date)
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
@@ -0,0 +1,196 @@
+package httpclient_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+func newRetryClient(t *testing.T, handler http.HandlerFunc, policy httpclient.RetryPolicy) (*httpclient.CoreClient, func()) {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	config := httpclient.CoreClientConfig{
+		URL:    server.URL,
+		APIKey: "use transport layer security",
+		CACert: server.Certificate(),
+		Retry:  policy,
+	}
+	return httpclient.NewCoreClient(config), server.Close
+}
+
+func TestClientRetry(t *testing.T) {
+	var attempts int32
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		if len(body) == 0 {
+			t.Error("empty request body")
+		}
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			http.Error(w, "restarting", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+	policy := httpclient.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		Jitter:      0.5,
+	}
+	cl, cleanup := newRetryClient(t, handler, policy)
+	defer cleanup()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	resp, err := cl.PutResource(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("bad status code: %d", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestClientRetryMaxAttempts(t *testing.T) {
+	var attempts int32
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}
+	policy := httpclient.RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+	}
+	cl, cleanup := newRetryClient(t, handler, policy)
+	defer cleanup()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	_, err := cl.DeleteResource(context.Background(), req)
+	httpErr, ok := err.(httpclient.HTTPError)
+	if !ok {
+		t.Fatalf("expected an HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusBadGateway || httpErr.Body != "bad gateway\n" {
+		t.Fatalf("bad error: %v", httpErr)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestClientRetryNotRetryable(t *testing.T) {
+	var attempts int32
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+	policy := httpclient.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	}
+	cl, cleanup := newRetryClient(t, handler, policy)
+	defer cleanup()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	if _, err := cl.DeleteResource(context.Background(), req); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestClientRetryConnectionError(t *testing.T) {
+	var attempts int32
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+	policy := httpclient.RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+	}
+	cl, cleanup := newRetryClient(t, handler, policy)
+	defer cleanup()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	if _, err := cl.PostResource(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestClientRetryAfterDeadline(t *testing.T) {
+	var attempts int32
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "restarting", http.StatusServiceUnavailable)
+	}
+	policy := httpclient.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	}
+	cl, cleanup := newRetryClient(t, handler, policy)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	start := time.Now()
+	_, err := cl.DeleteResource(ctx, req)
+	if httpErr, ok := err.(httpclient.HTTPError); !ok || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 HTTPError, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("waited %s for a retry past the deadline", elapsed)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	var attempts int32
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "restarting", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+	policy := httpclient.RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+	}
+	cl, cleanup := newRetryClient(t, handler, policy)
+	defer cleanup()
+
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	start := time.Now()
+	if _, err := cl.DeleteResource(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("Retry-After not honored, retried after %s", elapsed)
+	}
+}
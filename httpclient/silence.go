@@ -0,0 +1,213 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+)
+
+// EntitySubscription returns the subscription that every entity has, used to
+// silence a single entity.
+func EntitySubscription(entity string) string {
+	return "entity:" + entity
+}
+
+// SilenceOptions specifies a silence created with CoreClient.Silence. At
+// least one of Subscription, Entity or Check must be set.
+type SilenceOptions struct {
+	// Subscription silences the entities with the subscription.
+	Subscription string
+
+	// Entity silences a single entity. It can't be combined with
+	// Subscription.
+	Entity string
+
+	// Check silences the check, on all the entities unless Subscription or
+	// Entity are set.
+	Check string
+
+	// Expire is the duration of the silence, rounded up to the second. The
+	// silence doesn't expire when Expire is 0.
+	Expire time.Duration
+
+	// ExpireOnResolve clears the silence once the check resolves.
+	ExpireOnResolve bool
+
+	// Begin delays the start of the silence. The silence starts immediately
+	// when Begin is zero.
+	Begin time.Time
+
+	// Reason explains why the silence was created.
+	Reason string
+}
+
+// SilenceQuery selects silences by the subscription, entity or check they
+// apply to. Empty fields match all silences.
+type SilenceQuery struct {
+	Subscription string
+	Entity       string
+	Check        string
+}
+
+func (q SilenceQuery) matches(silence *corev2.Silenced) bool {
+	if q.Subscription != "" && silence.Subscription != q.Subscription {
+		return false
+	}
+	if q.Entity != "" && silence.Subscription != EntitySubscription(q.Entity) {
+		return false
+	}
+	if q.Check != "" && silence.Check != q.Check {
+		return false
+	}
+	return true
+}
+
+// Silence creates or replaces the silence in the namespace, and returns it.
+func (c *CoreClient) Silence(ctx context.Context, namespace string, options SilenceOptions) (*corev2.Silenced, error) {
+	subscription := options.Subscription
+	if options.Entity != "" {
+		if subscription != "" {
+			return nil, fmt.Errorf("can't silence both entity %s and subscription %s", options.Entity, subscription)
+		}
+		subscription = EntitySubscription(options.Entity)
+	}
+	name, err := corev2.SilencedName(subscription, options.Check)
+	if err != nil {
+		return nil, err
+	}
+	// the API counts seconds, round up so that short durations don't become
+	// silences that never expire
+	expire := int64(options.Expire / time.Second)
+	if options.Expire%time.Second > 0 {
+		expire++
+	}
+	silence := &corev2.Silenced{
+		ObjectMeta: corev2.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Subscription:    subscription,
+		Check:           options.Check,
+		Expire:          expire,
+		ExpireOnResolve: options.ExpireOnResolve,
+		Reason:          options.Reason,
+	}
+	if !options.Begin.IsZero() {
+		silence.Begin = options.Begin.Unix()
+	}
+	if _, err := c.PutResource(ctx, ResourceRequest{Resource: silence}); err != nil {
+		return nil, err
+	}
+	return silence, nil
+}
+
+// ListSilences lists all the silences in the namespace.
+func (c *CoreClient) ListSilences(ctx context.Context, namespace string) ([]*corev2.Silenced, error) {
+	req := ResourceRequest{
+		Resource: &corev2.Silenced{
+			ObjectMeta: corev2.ObjectMeta{Namespace: namespace},
+		},
+	}
+	resources, err := c.ListResources(ctx, req, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	silences := make([]*corev2.Silenced, 0, len(resources))
+	for _, resource := range resources {
+		silences = append(silences, resource.(*corev2.Silenced))
+	}
+	return silences, nil
+}
+
+// FindSilences lists the silences in the namespace that match the query.
+func (c *CoreClient) FindSilences(ctx context.Context, namespace string, query SilenceQuery) ([]*corev2.Silenced, error) {
+	silences, err := c.ListSilences(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var found []*corev2.Silenced
+	for _, silence := range silences {
+		if query.matches(silence) {
+			found = append(found, silence)
+		}
+	}
+	return found, nil
+}
+
+// ClearSilence deletes the silence with the given name, for example
+// "entity:server1:check-disk".
+func (c *CoreClient) ClearSilence(ctx context.Context, namespace, name string) error {
+	req := ResourceRequest{
+		Resource: &corev2.Silenced{
+			ObjectMeta: corev2.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+			},
+		},
+	}
+	_, err := c.DeleteResource(ctx, req)
+	return err
+}
+
+// ClearSilences deletes the silences in the namespace that match the query,
+// and returns the number of silences deleted.
+func (c *CoreClient) ClearSilences(ctx context.Context, namespace string, query SilenceQuery) (int, error) {
+	silences, err := c.FindSilences(ctx, namespace, query)
+	if err != nil {
+		return 0, err
+	}
+	for i, silence := range silences {
+		if err := c.ClearSilence(ctx, namespace, silence.Name); err != nil {
+			return i, err
+		}
+	}
+	return len(silences), nil
+}
+
+// EventSilences returns the silences that currently apply to the event. The
+// backend deletes the silences that expired, only the silences that didn't
+// begin yet are filtered out.
+func (c *CoreClient) EventSilences(ctx context.Context, event *corev2.Event) ([]*corev2.Silenced, error) {
+	if !event.HasCheck() || event.Entity == nil {
+		return nil, fmt.Errorf("event must contain an entity and a check")
+	}
+	silences, err := c.ListSilences(ctx, event.Entity.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions := map[string]bool{
+		EntitySubscription(event.Entity.Name): true,
+	}
+	for _, subscription := range event.Entity.Subscriptions {
+		subscriptions[subscription] = true
+	}
+	for _, subscription := range event.Check.Subscriptions {
+		subscriptions[subscription] = true
+	}
+	now := time.Now().Unix()
+	var applied []*corev2.Silenced
+	for _, silence := range silences {
+		if silence.Begin > now {
+			continue
+		}
+		if silence.Check != "" && silence.Check != event.Check.Name {
+			continue
+		}
+		if silence.Subscription != "" && !subscriptions[silence.Subscription] {
+			continue
+		}
+		applied = append(applied, silence)
+	}
+	return applied, nil
+}
+
+// IsEventSilenced returns true if a silence currently applies to the event.
+func (c *CoreClient) IsEventSilenced(ctx context.Context, event *corev2.Event) (bool, error) {
+	silences, err := c.EventSilences(ctx, event)
+	if err != nil {
+		return false, err
+	}
+	return len(silences) > 0, nil
+}
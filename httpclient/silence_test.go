@@ -0,0 +1,315 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+const silencedPath = "/api/core/v2/namespaces/default/silenced"
+
+// silencedBackend is a fake backend storing silences in memory. Like the
+// Sensu backend, it deletes expired silences and returns the remaining time
+// of the others as their expire.
+type silencedBackend struct {
+	mu       sync.Mutex
+	silences map[string]*corev2.Silenced
+	expires  map[string]time.Time
+}
+
+// put stores the silence as if it was created at created.
+func (b *silencedBackend) put(silence *corev2.Silenced, created time.Time) {
+	b.silences[silence.Name] = silence
+	delete(b.expires, silence.Name)
+	if silence.Expire > 0 {
+		start := time.Unix(silence.Begin, 0)
+		if start.Before(created) {
+			start = created
+		}
+		b.expires[silence.Name] = start.Add(time.Duration(silence.Expire) * time.Second)
+	}
+}
+
+func (b *silencedBackend) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if req.URL.Path == silencedPath && req.Method == http.MethodGet {
+		var names []string
+		for name := range b.silences {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		silences := []*corev2.Silenced{}
+		now := time.Now()
+		for _, name := range names {
+			silence := *b.silences[name]
+			if expires, ok := b.expires[name]; ok {
+				if !expires.After(now) {
+					delete(b.silences, name)
+					delete(b.expires, name)
+					continue
+				}
+				silence.Expire = int64(expires.Sub(now)/time.Second) + 1
+			}
+			silences = append(silences, &silence)
+		}
+		_ = json.NewEncoder(w).Encode(silences)
+		return
+	}
+	if !strings.HasPrefix(req.URL.Path, silencedPath+"/") {
+		http.NotFound(w, req)
+		return
+	}
+	name, err := url.PathUnescape(strings.TrimPrefix(req.URL.EscapedPath(), silencedPath+"/"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch req.Method {
+	case http.MethodPut:
+		silence := new(corev2.Silenced)
+		if err := json.NewDecoder(req.Body).Decode(silence); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if silence.Name != name {
+			http.Error(w, "name mismatch", http.StatusBadRequest)
+			return
+		}
+		if silence.Begin == 0 {
+			silence.Begin = time.Now().Unix()
+		}
+		b.put(silence, time.Now())
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		if _, ok := b.silences[name]; !ok {
+			http.NotFound(w, req)
+			return
+		}
+		delete(b.silences, name)
+		delete(b.expires, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func newSilencedClient(t *testing.T) (*httpclient.CoreClient, *silencedBackend, func()) {
+	t.Helper()
+	backend := &silencedBackend{silences: map[string]*corev2.Silenced{}, expires: map[string]time.Time{}}
+	server := httptest.NewTLSServer(backend)
+	config := httpclient.CoreClientConfig{
+		URL:    server.URL,
+		APIKey: "use transport layer security",
+		CACert: server.Certificate(),
+	}
+	return httpclient.NewCoreClient(config), backend, server.Close
+}
+
+func silenceNames(silences []*corev2.Silenced) string {
+	var names []string
+	for _, silence := range silences {
+		names = append(names, silence.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestClientSilences(t *testing.T) {
+	cl, backend, cleanup := newSilencedClient(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	silence, err := cl.Silence(ctx, "default", httpclient.SilenceOptions{
+		Entity:          "server1",
+		Check:           "check-disk",
+		Expire:          time.Hour,
+		ExpireOnResolve: true,
+		Reason:          "remediation in progress",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := silence.Name, "entity:server1:check-disk"; got != want {
+		t.Fatalf("bad silence name: got %q, want %q", got, want)
+	}
+	stored := backend.silences[silence.Name]
+	if stored == nil || stored.Expire != 3600 || !stored.ExpireOnResolve || stored.Reason != "remediation in progress" {
+		t.Fatalf("bad stored silence: %+v", stored)
+	}
+	if _, err := cl.Silence(ctx, "default", httpclient.SilenceOptions{Subscription: "linux"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cl.Silence(ctx, "default", httpclient.SilenceOptions{Check: "check-disk"}); err != nil {
+		t.Fatal(err)
+	}
+
+	silences, err := cl.ListSilences(ctx, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := silenceNames(silences), "*:check-disk,entity:server1:check-disk,linux:*"; got != want {
+		t.Fatalf("bad silences: got %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		query httpclient.SilenceQuery
+		want  string
+	}{
+		{httpclient.SilenceQuery{Check: "check-disk"}, "*:check-disk,entity:server1:check-disk"},
+		{httpclient.SilenceQuery{Entity: "server1"}, "entity:server1:check-disk"},
+		{httpclient.SilenceQuery{Subscription: "linux"}, "linux:*"},
+		{httpclient.SilenceQuery{Entity: "server2"}, ""},
+	}
+	for _, test := range tests {
+		found, err := cl.FindSilences(ctx, "default", test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := silenceNames(found); got != test.want {
+			t.Errorf("bad silences for %+v: got %q, want %q", test.query, got, test.want)
+		}
+	}
+
+	cleared, err := cl.ClearSilences(ctx, "default", httpclient.SilenceQuery{Check: "check-disk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cleared != 2 {
+		t.Fatalf("expected 2 cleared silences, got %d", cleared)
+	}
+	if err := cl.ClearSilence(ctx, "default", "linux:*"); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.silences) != 0 {
+		t.Fatalf("silences left: %v", backend.silences)
+	}
+}
+
+func TestClientSilenceExpireRounding(t *testing.T) {
+	cl, backend, cleanup := newSilencedClient(t)
+	defer cleanup()
+
+	tests := []struct {
+		expire time.Duration
+		want   int64
+	}{
+		{0, 0},
+		{500 * time.Millisecond, 1},
+		{time.Second, 1},
+		{1500 * time.Millisecond, 2},
+	}
+	for _, test := range tests {
+		silence, err := cl.Silence(context.Background(), "default", httpclient.SilenceOptions{Check: "check-disk", Expire: test.expire})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := backend.silences[silence.Name].Expire; got != test.want {
+			t.Errorf("bad expire for %s: got %d, want %d", test.expire, got, test.want)
+		}
+	}
+}
+
+func TestClientSilenceInvalid(t *testing.T) {
+	cl, _, cleanup := newSilencedClient(t)
+	defer cleanup()
+
+	if _, err := cl.Silence(context.Background(), "default", httpclient.SilenceOptions{}); err == nil {
+		t.Error("expected an error for an empty silence")
+	}
+	options := httpclient.SilenceOptions{Entity: "server1", Subscription: "linux"}
+	if _, err := cl.Silence(context.Background(), "default", options); err == nil {
+		t.Error("expected an error for an entity and subscription silence")
+	}
+}
+
+func TestClientIsEventSilenced(t *testing.T) {
+	cl, backend, cleanup := newSilencedClient(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	event := corev2.FixtureEvent("server1", "check-disk")
+	event.Entity.Subscriptions = []string{"linux"}
+	event.Check.Subscriptions = []string{"storage"}
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		silence  *corev2.Silenced
+		silenced bool
+	}{
+		{
+			name:     "entity and check",
+			silence:  &corev2.Silenced{Subscription: "entity:server1", Check: "check-disk"},
+			silenced: true,
+		},
+		{
+			name:     "entity subscription",
+			silence:  &corev2.Silenced{Subscription: "linux"},
+			silenced: true,
+		},
+		{
+			name:     "check subscription",
+			silence:  &corev2.Silenced{Subscription: "storage"},
+			silenced: true,
+		},
+		{
+			name:     "check",
+			silence:  &corev2.Silenced{Check: "check-disk"},
+			silenced: true,
+		},
+		{
+			name:    "other check",
+			silence: &corev2.Silenced{Subscription: "linux", Check: "check-cpu"},
+		},
+		{
+			name:    "other entity",
+			silence: &corev2.Silenced{Subscription: "entity:server2"},
+		},
+		{
+			name:    "not started",
+			silence: &corev2.Silenced{Check: "check-disk", Begin: now.Add(time.Hour).Unix()},
+		},
+		{
+			name:     "past half of its duration",
+			silence:  &corev2.Silenced{Check: "check-disk", Begin: now.Add(-time.Hour).Unix(), Expire: int64((90 * time.Minute) / time.Second)},
+			silenced: true,
+		},
+		{
+			name:    "expired",
+			silence: &corev2.Silenced{Check: "check-disk", Begin: now.Add(-time.Hour).Unix(), Expire: int64((59 * time.Minute) / time.Second)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name, err := corev2.SilencedName(test.silence.Subscription, test.silence.Check)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.silence.Namespace = "default"
+			test.silence.Name = name
+			backend.mu.Lock()
+			backend.silences = map[string]*corev2.Silenced{}
+			backend.expires = map[string]time.Time{}
+			backend.put(test.silence, now.Add(-time.Hour))
+			backend.mu.Unlock()
+
+			silenced, err := cl.IsEventSilenced(ctx, event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if silenced != test.silenced {
+				t.Fatalf("bad silenced status: got %v, want %v", silenced, test.silenced)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// keyPairReloader provides a client certificate loaded from a certificate
+// file and a key file, and loads it again when either file is modified.
+type keyPairReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+func newKeyPairReloader(certFile, keyFile string) *keyPairReloader {
+	return &keyPairReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+}
+
+// GetClientCertificate returns the current key pair, and is suitable for
+// tls.Config.GetClientCertificate.
+func (r *keyPairReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.keyPair()
+}
+
+func (r *keyPairReloader) keyPair() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return r.fallback(fmt.Errorf("error loading client certificate: %s", err))
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return r.fallback(fmt.Errorf("error loading client key: %s", err))
+	}
+	if r.cert != nil && certInfo.ModTime().Equal(r.certMod) && keyInfo.ModTime().Equal(r.keyMod) {
+		return r.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return r.fallback(fmt.Errorf("error loading client key pair: %s", err))
+	}
+	r.cert = &cert
+	r.certMod = certInfo.ModTime()
+	r.keyMod = keyInfo.ModTime()
+	return r.cert, nil
+}
+
+// fallback returns the previously loaded key pair, if any, when loading a
+// new one fails. This happens while the files are being rotated, when only
+// one of them has been replaced.
+func (r *keyPairReloader) fallback(err error) (*tls.Certificate, error) {
+	if r.cert != nil {
+		return r.cert, nil
+	}
+	return nil, err
+}
+
+func setClientCertificate(client *http.Client, reloader *keyPairReloader) {
+	if client.Transport == nil {
+		client.Transport = new(http.Transport)
+	}
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = new(tls.Config)
+		}
+		transport.TLSClientConfig.GetClientCertificate = reloader.GetClientCertificate
+	}
+}
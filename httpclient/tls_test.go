@@ -0,0 +1,192 @@
+package httpclient_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+// testCA issues client certificates for the mutual TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+// writeClientCert issues a client certificate and writes it along with its
+// key to certFile and keyFile.
+func (ca *testCA) writeClientCert(t *testing.T, name, certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := ioutil.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// touch moves the modification time of the files forward, so that file
+// systems with a coarse timestamp resolution see them as modified.
+func touch(t *testing.T, modified time.Time, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		if err := os.Chtimes(file, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestClientCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	var mu sync.Mutex
+	var names []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		names = append(names, req.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "httpclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	ca.writeClientCert(t, "client-1", certFile, keyFile)
+
+	config := httpclient.CoreClientConfig{
+		URL:        server.URL,
+		APIKey:     "use transport layer security",
+		CACert:     server.Certificate(),
+		ClientCert: certFile,
+		ClientKey:  keyFile,
+	}
+	cl := httpclient.NewCoreClient(config)
+	cl.HTTPClient.Transport.(*http.Transport).DisableKeepAlives = true
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	if _, err := cl.DeleteResource(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate the key pair
+	ca.writeClientCert(t, "client-2", certFile, keyFile)
+	touch(t, time.Now().Add(time.Minute), certFile, keyFile)
+	if _, err := cl.DeleteResource(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	// A half rotated key pair keeps the previous one in use
+	if err := ioutil.WriteFile(keyFile, []byte("rotating"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, time.Now().Add(2*time.Minute), keyFile)
+	if _, err := cl.DeleteResource(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"client-1", "client-2", "client-2"}
+	if len(names) != len(want) {
+		t.Fatalf("bad client certificates: %v", names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("bad client certificates: got %v, want %v", names, want)
+		}
+	}
+}
+
+func TestClientCertificateMissing(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	config := httpclient.CoreClientConfig{
+		URL:        server.URL,
+		APIKey:     "use transport layer security",
+		CACert:     server.Certificate(),
+		ClientCert: "/nonexistent/client.crt",
+		ClientKey:  "/nonexistent/client.key",
+	}
+	cl := httpclient.NewCoreClient(config)
+	req := httpclient.ResourceRequest{Resource: corev2.FixtureCheckConfig("fake")}
+	if _, err := cl.DeleteResource(context.Background(), req); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,177 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+// DefaultWatchInterval is the polling interval of Watch when the Interval of
+// the WatchOptions is not set.
+const DefaultWatchInterval = 10 * time.Second
+
+// WatchAction is the kind of change reported by a WatchEvent.
+type WatchAction string
+
+const (
+	// WatchCreate reports a resource that appeared.
+	WatchCreate WatchAction = "create"
+
+	// WatchUpdate reports a resource that changed.
+	WatchUpdate WatchAction = "update"
+
+	// WatchDelete reports a resource that disappeared.
+	WatchDelete WatchAction = "delete"
+
+	// WatchError reports a failure to fetch the resources. The watch keeps
+	// going, and tries again after a delay.
+	WatchError WatchAction = "error"
+)
+
+// WatchEvent is a change notification delivered by Watch.
+type WatchEvent struct {
+	Action WatchAction
+
+	// Resource is the created or updated resource, or the last known state
+	// of the deleted resource. It is nil for WatchError events.
+	Resource types.Resource
+
+	// Err is the error of WatchError events.
+	Err error
+}
+
+// WatchOptions specifies the resources watched by Watch and how often they
+// are polled.
+type WatchOptions struct {
+	ListOptions
+
+	// Interval is the delay between two polls of the resources.
+	Interval time.Duration
+}
+
+// Watch watches the resources of a type in a namespace for changes, and
+// delivers the changes on the returned channel until the context is
+// cancelled. Use NewListRequest to create the ResourceRequest.
+//
+// The resources are polled every Interval, using entity tags to avoid
+// transferring resources that did not change when the backend supports them.
+// All the resources present on the first poll are reported as created. When
+// polling fails, a WatchError event is delivered and the poll is retried with
+// the backoff of the client retry policy, waiting at least Interval.
+//
+// The channel is closed once the context is cancelled. Callers must keep
+// receiving from the channel until it is closed.
+func (c *CoreClient) Watch(ctx context.Context, r ResourceRequest, options WatchOptions) <-chan WatchEvent {
+	events := make(chan WatchEvent)
+	go c.watch(ctx, r, options, events)
+	return events
+}
+
+// watchedResource is the last known state of a watched resource.
+type watchedResource struct {
+	raw      json.RawMessage
+	resource types.Resource
+}
+
+func (c *CoreClient) watch(ctx context.Context, r ResourceRequest, options WatchOptions, events chan<- WatchEvent) {
+	defer close(events)
+	interval := options.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	send := func(event WatchEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	known := map[string]watchedResource{}
+	var etag string
+	failures := 0
+	for {
+		delay := interval
+		result, err := c.list(ctx, r, options.ListOptions, etag)
+		if err == nil && !result.notModified {
+			var changes []WatchEvent
+			changes, err = diffResources(r, known, result.items)
+			if err == nil {
+				etag = result.etag
+				for _, change := range changes {
+					if !send(change) {
+						return
+					}
+				}
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			// back off from a failing backend, without polling it more often
+			// than when it works
+			if backoff := c.Config.Retry.delay(failures, nil); backoff > delay {
+				delay = backoff
+			}
+			if !send(WatchEvent{Action: WatchError, Err: err}) {
+				return
+			}
+		} else {
+			failures = 0
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// diffResources updates the known resources with the listed ones, and
+// returns the changes. Resources are identified by their URI path.
+func diffResources(r ResourceRequest, known map[string]watchedResource, items []json.RawMessage) ([]WatchEvent, error) {
+	var changes []WatchEvent
+	listed := make(map[string]watchedResource, len(items))
+	for _, item := range items {
+		resource, err := decodeResource(r, item)
+		if err != nil {
+			return nil, err
+		}
+		key := resource.URIPath()
+		listed[key] = watchedResource{raw: item, resource: resource}
+		previous, ok := known[key]
+		switch {
+		case !ok:
+			changes = append(changes, WatchEvent{Action: WatchCreate, Resource: resource})
+		case !bytes.Equal(previous.raw, item):
+			changes = append(changes, WatchEvent{Action: WatchUpdate, Resource: resource})
+		}
+	}
+	var deleted []string
+	for key := range known {
+		if _, ok := listed[key]; !ok {
+			deleted = append(deleted, key)
+		}
+	}
+	sort.Strings(deleted)
+	for _, key := range deleted {
+		changes = append(changes, WatchEvent{Action: WatchDelete, Resource: known[key].resource})
+	}
+	for key := range known {
+		delete(known, key)
+	}
+	for key, resource := range listed {
+		known[key] = resource
+	}
+	return changes, nil
+}
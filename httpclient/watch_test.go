@@ -0,0 +1,193 @@
+package httpclient_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-plugin-sdk/httpclient"
+)
+
+// checksBackend is a fake backend serving a list of checks with entity tags.
+type checksBackend struct {
+	mu          sync.Mutex
+	checks      []*corev2.CheckConfig
+	failures    int
+	notModified int
+	requests    []time.Time
+}
+
+func (b *checksBackend) set(checks ...*corev2.CheckConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.checks = checks
+}
+
+func (b *checksBackend) fail(failures int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = failures
+}
+
+func (b *checksBackend) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.requests = append(b.requests, time.Now())
+	if b.failures > 0 {
+		b.failures--
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	body, err := json.Marshal(b.checks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+	if req.Header.Get("If-None-Match") == etag {
+		b.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write(body)
+}
+
+func receiveWatchEvent(t *testing.T, events <-chan httpclient.WatchEvent) httpclient.WatchEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("watch channel closed")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a watch event")
+	}
+	return httpclient.WatchEvent{}
+}
+
+func expectWatchEvent(t *testing.T, events <-chan httpclient.WatchEvent, action httpclient.WatchAction, name string) *corev2.CheckConfig {
+	t.Helper()
+	event := receiveWatchEvent(t, events)
+	if event.Action != action {
+		t.Fatalf("bad action: got %q, want %q (%v)", event.Action, action, event.Err)
+	}
+	check, ok := event.Resource.(*corev2.CheckConfig)
+	if !ok {
+		t.Fatalf("bad resource type: %T", event.Resource)
+	}
+	if check.Name != name {
+		t.Fatalf("bad resource: got %q, want %q", check.Name, name)
+	}
+	return check
+}
+
+func TestClientWatch(t *testing.T) {
+	backend := new(checksBackend)
+	backend.set(corev2.FixtureCheckConfig("disk"), corev2.FixtureCheckConfig("cpu"))
+	server := httptest.NewTLSServer(backend)
+	defer server.Close()
+
+	config := httpclient.CoreClientConfig{
+		URL:    server.URL,
+		APIKey: "use transport layer security",
+		CACert: server.Certificate(),
+	}
+	cl := httpclient.NewCoreClient(config)
+	req, err := httpclient.NewListRequest("core/v2", "CheckConfig", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	events := cl.Watch(ctx, req, httpclient.WatchOptions{Interval: 10 * time.Millisecond})
+
+	expectWatchEvent(t, events, httpclient.WatchCreate, "disk")
+	expectWatchEvent(t, events, httpclient.WatchCreate, "cpu")
+
+	// Unchanged resources are not transferred again
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		backend.mu.Lock()
+		notModified := backend.notModified
+		backend.mu.Unlock()
+		if notModified > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("entity tags were not used")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cpu := corev2.FixtureCheckConfig("cpu")
+	cpu.Interval = 30
+	backend.set(cpu, corev2.FixtureCheckConfig("memory"))
+	if check := expectWatchEvent(t, events, httpclient.WatchUpdate, "cpu"); check.Interval != 30 {
+		t.Errorf("bad interval: %d", check.Interval)
+	}
+	expectWatchEvent(t, events, httpclient.WatchCreate, "memory")
+	expectWatchEvent(t, events, httpclient.WatchDelete, "disk")
+
+	backend.fail(1)
+	if event := receiveWatchEvent(t, events); event.Action != httpclient.WatchError || event.Err == nil {
+		t.Fatalf("expected an error event, got %v", event)
+	}
+	backend.set(cpu)
+	expectWatchEvent(t, events, httpclient.WatchDelete, "memory")
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestClientWatchErrorBackoff(t *testing.T) {
+	backend := new(checksBackend)
+	backend.set(corev2.FixtureCheckConfig("disk"))
+	backend.fail(2)
+	server := httptest.NewTLSServer(backend)
+	defer server.Close()
+
+	config := httpclient.CoreClientConfig{
+		URL:    server.URL,
+		APIKey: "use transport layer security",
+		CACert: server.Certificate(),
+		Retry:  httpclient.RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	}
+	cl := httpclient.NewCoreClient(config)
+	req, err := httpclient.NewListRequest("core/v2", "CheckConfig", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	interval := 200 * time.Millisecond
+	events := cl.Watch(ctx, req, httpclient.WatchOptions{Interval: interval})
+
+	for i := 0; i < 2; i++ {
+		if event := receiveWatchEvent(t, events); event.Action != httpclient.WatchError {
+			t.Fatalf("expected an error event, got %v", event)
+		}
+	}
+	expectWatchEvent(t, events, httpclient.WatchCreate, "disk")
+	cancel()
+	for range events {
+	}
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.requests) < 3 {
+		t.Fatalf("expected 3 requests, got %d", len(backend.requests))
+	}
+	for i := 1; i < 3; i++ {
+		if wait := backend.requests[i].Sub(backend.requests[i-1]); wait < interval {
+			t.Errorf("poll %d retried after %s, sooner than the interval", i, wait)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package sensu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+// CheckResult is the result of a check execution. The SDK renders it to
+// stdout in the conventional plugin output format:
+//
+//	CHECKNAME STATUS: Output | perfdata
+//	LongOutput...
+//
+// and exits with Status.
+type CheckResult struct {
+	// Status is the check status, one of the CheckState constants.
+	Status int
+
+	// Output is the message printed on the first line of the check output.
+	Output string
+
+	// LongOutput lines are printed after the first line of the check output.
+	LongOutput []string
+
+	// Metrics are printed as performance data on the first line when the
+	// nagios_perfdata metric format is selected, or after the long output for
+	// the other formats.
+	Metrics []*types.MetricPoint
+}
+
+// CheckStateName returns the name of a check status, such as "OK" or
+// "CRITICAL". Statuses outside of the CheckState constants are "UNKNOWN".
+func CheckStateName(status int) string {
+	switch status {
+	case CheckStateOK:
+		return "OK"
+	case CheckStateWarning:
+		return "WARNING"
+	case CheckStateCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// render formats the check result for the check named name, writing metrics
+// in the given format.
+func (r *CheckResult) render(name, metricFormat string) (string, error) {
+	metrics, err := FormatMetrics(metricFormat, r.Metrics)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s", name, CheckStateName(r.Status)))
+	if len(r.Output) > 0 {
+		sb.WriteString(": ")
+		sb.WriteString(r.Output)
+	}
+	if metricFormat == MetricFormatNagios && len(r.Metrics) > 0 {
+		sb.WriteString(" | ")
+		sb.WriteString(metrics)
+	}
+	sb.WriteByte('\n')
+	for _, line := range r.LongOutput {
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	if metricFormat != MetricFormatNagios {
+		sb.WriteString(metrics)
+	}
+	return sb.String(), nil
+}
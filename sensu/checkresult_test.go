@@ -0,0 +1,45 @@
+package sensu
+
+import (
+	"testing"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStateName(t *testing.T) {
+	assert.Equal(t, "OK", CheckStateName(CheckStateOK))
+	assert.Equal(t, "WARNING", CheckStateName(CheckStateWarning))
+	assert.Equal(t, "CRITICAL", CheckStateName(CheckStateCritical))
+	assert.Equal(t, "UNKNOWN", CheckStateName(CheckStateUnknown))
+	assert.Equal(t, "UNKNOWN", CheckStateName(127))
+}
+
+func TestCheckResult_Render(t *testing.T) {
+	point := NewMetricPoint("used", 42, nil)
+	point.Timestamp = 1600000000
+	result := &CheckResult{
+		Status:     CheckStateWarning,
+		Output:     "disk usage is 42%",
+		LongOutput: []string{"/var 42%", "/ 12%"},
+		Metrics:    []*types.MetricPoint{point},
+	}
+
+	output, err := result.render("CheckDisk", MetricFormatNagios)
+	assert.NoError(t, err)
+	assert.Equal(t, "CheckDisk WARNING: disk usage is 42% | used=42\n/var 42%\n/ 12%\n", output)
+
+	output, err = result.render("CheckDisk", MetricFormatGraphite)
+	assert.NoError(t, err)
+	assert.Equal(t, "CheckDisk WARNING: disk usage is 42%\n/var 42%\n/ 12%\nused 42 1600000000\n", output)
+
+	_, err = result.render("CheckDisk", "invalid")
+	assert.Error(t, err)
+}
+
+func TestCheckResult_RenderNoOutput(t *testing.T) {
+	result := &CheckResult{Status: CheckStateOK}
+	output, err := result.render("CheckDisk", MetricFormatNagios)
+	assert.NoError(t, err)
+	assert.Equal(t, "CheckDisk OK\n", output)
+}
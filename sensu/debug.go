@@ -0,0 +1,17 @@
+package sensu
+
+// DebugOption adds the following flag to a plugin:
+//
+//	--debug
+//
+// Plugins can enable the debug logging of the CoreClient in the httpclient
+// package with it, by setting the Debug field of its config.
+func DebugOption(debug *bool) *PluginConfigOption {
+	return &PluginConfigOption{
+		Value:    debug,
+		Path:     "debug",
+		Env:      "SENSU_PLUGIN_DEBUG",
+		Argument: "debug",
+		Usage:    "Enable debug logging, including the requests sent to the Sensu API",
+	}
+}
@@ -0,0 +1,41 @@
+package sensu
+
+import (
+	"testing"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugOption(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		env   map[string]string
+		debug bool
+	}{
+		{name: "default"},
+		{name: "argument", args: []string{"--debug"}, debug: true},
+		{name: "environment", env: map[string]string{"SENSU_PLUGIN_DEBUG": "true"}, debug: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var debug bool
+			config := &PluginConfig{Name: "debug-check", Short: "debug check"}
+			check := NewGoCheck(config, []*PluginConfigOption{DebugOption(&debug)},
+				func(*types.Event) (int, error) {
+					return CheckStateOK, nil
+				},
+				func(*types.Event) (int, error) {
+					return CheckStateOK, nil
+				}, false)
+			env := test.env
+			if env == nil {
+				env = map[string]string{}
+			}
+			status := check.ExecuteWithEnvironment(Environment{Args: test.args, Env: env})
+			assert.Equal(t, CheckStateOK, status)
+			assert.Equal(t, test.debug, debug)
+		})
+	}
+}
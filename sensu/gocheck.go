@@ -1,9 +1,12 @@
 package sensu
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sync"
 
 	"github.com/sensu/sensu-go/types"
 )
@@ -17,38 +20,148 @@ const (
 
 type GoCheck struct {
 	basePlugin
+	metricFormat       *string
+	emitMetrics        bool
+	emitResult         bool
 	validationFunction func(event *types.Event) (int, error)
-	executeFunction    func(event *types.Event) (int, error)
+	executeFunction    func(ctx context.Context, event *types.Event) (*CheckResult, error)
+
+	// resultMu guards resultCtx, the context of the current execution, and
+	// resultWritten, so that a result check writes a single result when it
+	// times out, and executions that timed out don't write their result
+	resultMu      sync.Mutex
+	resultCtx     context.Context
+	resultWritten bool
 }
 
 func NewGoCheck(config *PluginConfig, options []*PluginConfigOption,
 	validationFunction func(*types.Event) (int, error),
 	executeFunction func(*types.Event) (int, error), readEvent bool) *GoCheck {
+	return NewGoCheckWithContext(config, options, validationFunction,
+		func(_ context.Context, event *types.Event) (int, error) {
+			return executeFunction(event)
+		}, readEvent)
+}
+
+// NewGoCheckWithContext creates a check whose execute function receives a
+// context. The context is cancelled when the check exceeds the Timeout set in
+// its PluginConfig, in which case the check exits with CheckStateUnknown.
+func NewGoCheckWithContext(config *PluginConfig, options []*PluginConfigOption,
+	validationFunction func(*types.Event) (int, error),
+	executeFunction func(context.Context, *types.Event) (int, error), readEvent bool) *GoCheck {
 	check := &GoCheck{
-		basePlugin: basePlugin{
-			config:                 config,
-			options:                options,
-			sensuEvent:             nil,
-			eventReader:            os.Stdin,
-			eventValidation:        false,
-			readEvent:              readEvent,
-			configurationOverrides: true,
-			errorExitStatus:        1,
+		validationFunction: validationFunction,
+		executeFunction: func(ctx context.Context, event *types.Event) (*CheckResult, error) {
+			status, err := executeFunction(ctx, event)
+			return &CheckResult{Status: status}, err
 		},
+	}
+	check.initCheck(config, options, readEvent)
+	return check
+}
+
+// NewGoCheckWithMetrics creates a check whose execute function returns metric
+// points along with the check status. The SDK writes the points to stdout in
+// the format selected with the standard --metric-format option, which should
+// match the output_metric_format of the check definition. If the
+// nagios_perfdata format is selected, the output is the check name followed by
+// the performance data. An invalid format exits with CheckStateUnknown before
+// the check is executed. A check can define its own --metric-format option,
+// with a string value, to replace the standard one.
+func NewGoCheckWithMetrics(config *PluginConfig, options []*PluginConfigOption,
+	validationFunction func(*types.Event) (int, error),
+	executeFunction func(context.Context, *types.Event) (int, []*types.MetricPoint, error), readEvent bool) *GoCheck {
+	check := &GoCheck{
+		emitMetrics:        true,
+		validationFunction: validationFunction,
+		executeFunction: func(ctx context.Context, event *types.Event) (*CheckResult, error) {
+			status, points, err := executeFunction(ctx, event)
+			return &CheckResult{Status: status, Metrics: points}, err
+		},
+	}
+	options, check.metricFormat = withMetricFormatOption(options)
+	check.initCheck(config, options, readEvent)
+	return check
+}
+
+// NewGoCheckWithResult creates a check whose execute function returns a
+// CheckResult. The SDK renders the result to stdout and exits with its
+// status. Validation and execution errors, and timeouts, are reported on
+// stdout as an UNKNOWN result, so they are visible in the check output, and
+// statuses outside of the CheckState constants are reported as UNKNOWN.
+// Metrics in the result are written in the format selected with the standard
+// --metric-format option.
+func NewGoCheckWithResult(config *PluginConfig, options []*PluginConfigOption,
+	validationFunction func(*types.Event) (int, error),
+	executeFunction func(context.Context, *types.Event) (*CheckResult, error), readEvent bool) *GoCheck {
+	check := &GoCheck{
+		emitResult:         true,
 		validationFunction: validationFunction,
 		executeFunction:    executeFunction,
 	}
+	options, check.metricFormat = withMetricFormatOption(options)
+	check.initCheck(config, options, readEvent)
+	return check
+}
 
-	check.pluginWorkflowFunction = check.goCheckWorkflow
-	if err := check.initPlugin(); err != nil {
-		log.Printf("failed to initialize check plugin: %s", err)
+// withMetricFormatOption returns a copy of options with the standard
+// --metric-format option appended, and the value of the option. If the check
+// already has a --metric-format option with a string value, that option is
+// used instead.
+func withMetricFormatOption(options []*PluginConfigOption) ([]*PluginConfigOption, *string) {
+	for _, opt := range options {
+		if format, ok := opt.Value.(*string); ok && opt.Argument == metricFormatArgument {
+			return options, format
+		}
 	}
+	format := new(string)
+	checkOptions := make([]*PluginConfigOption, 0, len(options)+1)
+	checkOptions = append(checkOptions, options...)
+	return append(checkOptions, metricFormatOption(format)), format
+}
 
-	return check
+func (goCheck *GoCheck) initCheck(config *PluginConfig, options []*PluginConfigOption, readEvent bool) {
+	goCheck.basePlugin = basePlugin{
+		config:                 config,
+		options:                options,
+		sensuEvent:             nil,
+		eventReader:            os.Stdin,
+		eventValidation:        false,
+		readEvent:              readEvent,
+		configurationOverrides: true,
+		errorExitStatus:        1,
+		timeoutExitStatus:      CheckStateUnknown,
+		out:                    os.Stdout,
+	}
+
+	goCheck.pluginWorkflowFunction = goCheck.goCheckWorkflow
+	if goCheck.emitResult {
+		goCheck.timeoutFunction = goCheck.writeTimeoutResult
+	}
+	if err := goCheck.initPlugin(); err != nil {
+		log.Printf("failed to initialize check plugin: %s", err)
+	}
 }
 
 // Executes the check
-func (goCheck *GoCheck) goCheckWorkflow(_ []string) (int, error) {
+func (goCheck *GoCheck) goCheckWorkflow(ctx context.Context, _ []string) (int, error) {
+	// Validate the metric format before the check runs, not after
+	var formatErr error
+	if goCheck.emitMetrics || goCheck.emitResult {
+		_, formatErr = FormatMetrics(*goCheck.metricFormat, nil)
+	}
+
+	if goCheck.emitResult {
+		goCheck.startResult(ctx)
+		if formatErr != nil {
+			return goCheck.writeResult(ctx, &CheckResult{Status: CheckStateUnknown, Output: formatErr.Error()})
+		}
+		return goCheck.writeResult(ctx, goCheck.executeResult(ctx))
+	}
+	if formatErr != nil {
+		return CheckStateUnknown, formatErr
+	}
+
 	// Validate input using validateFunction
 	status, err := goCheck.validationFunction(goCheck.sensuEvent)
 	if err != nil {
@@ -56,10 +169,95 @@ func (goCheck *GoCheck) goCheckWorkflow(_ []string) (int, error) {
 	}
 
 	// Execute check logic using executeFunction
-	status, err = goCheck.executeFunction(goCheck.sensuEvent)
+	result, err := goCheck.executeFunction(ctx, goCheck.sensuEvent)
 	if err != nil {
-		return status, fmt.Errorf("error executing check: %s", err)
+		return result.Status, fmt.Errorf("error executing check: %s", err)
+	}
+
+	if goCheck.emitMetrics {
+		output, err := FormatMetrics(*goCheck.metricFormat, result.Metrics)
+		if err != nil {
+			return CheckStateUnknown, err
+		}
+		if *goCheck.metricFormat == MetricFormatNagios {
+			output = fmt.Sprintf("%s | %s\n", goCheck.config.Name, output)
+		}
+		_, _ = fmt.Fprint(goCheck.out, output)
 	}
 
+	return result.Status, nil
+}
+
+// executeResult runs the validation and execute functions of a check created
+// with NewGoCheckWithResult, turning errors into an UNKNOWN result.
+func (goCheck *GoCheck) executeResult(ctx context.Context) *CheckResult {
+	if _, err := goCheck.validationFunction(goCheck.sensuEvent); err != nil {
+		return &CheckResult{Status: CheckStateUnknown, Output: fmt.Sprintf("error validating input: %s", err)}
+	}
+
+	result, err := goCheck.executeFunction(ctx, goCheck.sensuEvent)
+	if err == nil && result == nil {
+		err = errors.New("no check result returned")
+	}
+	if err != nil {
+		return &CheckResult{Status: CheckStateUnknown, Output: fmt.Sprintf("error executing check: %s", err)}
+	}
+	return result
+}
+
+// startResult makes ctx the context of the current execution, unless it
+// already timed out.
+func (goCheck *GoCheck) startResult(ctx context.Context) {
+	goCheck.resultMu.Lock()
+	defer goCheck.resultMu.Unlock()
+	if goCheck.resultCtx != ctx && ctx.Err() == nil {
+		goCheck.resultCtx = ctx
+		goCheck.resultWritten = false
+	}
+}
+
+// writeResult renders the check result of the execution run with ctx to
+// stdout and returns its status.
+func (goCheck *GoCheck) writeResult(ctx context.Context, result *CheckResult) (int, error) {
+	status, _ := goCheck.writeResultOnce(ctx, result, false)
 	return status, nil
 }
+
+// writeTimeoutResult writes an UNKNOWN result for the execution run with ctx
+// when it times out, unless its result was already written.
+func (goCheck *GoCheck) writeTimeoutResult(ctx context.Context, err error) bool {
+	_, written := goCheck.writeResultOnce(ctx, &CheckResult{Status: CheckStateUnknown, Output: err.Error()}, true)
+	return written
+}
+
+// writeResultOnce renders the check result to stdout, unless a result was
+// already written for the execution run with ctx or ctx isn't the context of
+// the current execution, and returns its status. The timeout result is
+// written for the current execution, which ctx always is when it times out.
+// Statuses outside of the CheckState constants are reported as
+// CheckStateUnknown, so that the output matches the exit status.
+func (goCheck *GoCheck) writeResultOnce(ctx context.Context, result *CheckResult, timeout bool) (int, bool) {
+	if result.Status < CheckStateOK || result.Status > CheckStateUnknown {
+		clamped := *result
+		clamped.Status = CheckStateUnknown
+		result = &clamped
+	}
+	output, err := result.render(goCheck.config.Name, *goCheck.metricFormat)
+	if err != nil {
+		result = &CheckResult{Status: CheckStateUnknown, Output: err.Error()}
+		output, _ = result.render(goCheck.config.Name, MetricFormatNagios)
+	}
+
+	goCheck.resultMu.Lock()
+	defer goCheck.resultMu.Unlock()
+	if timeout && goCheck.resultCtx != ctx {
+		goCheck.resultCtx = ctx
+		goCheck.resultWritten = false
+	}
+	if goCheck.resultCtx != ctx || goCheck.resultWritten {
+		return CheckStateUnknown, false
+	}
+	goCheck.resultWritten = true
+	_, _ = fmt.Fprint(goCheck.out, output)
+	return result.Status, true
+}
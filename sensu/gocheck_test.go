@@ -1,8 +1,14 @@
 package sensu
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -48,6 +54,242 @@ func TestNewGoCheck(t *testing.T) {
 	assert.Equal(t, os.Stdin, goCheck.eventReader)
 }
 
+func TestGoCheck_Execute_Timeout(t *testing.T) {
+	values := &checkValues{}
+	options := getCheckOptions(values)
+	checkConfig := defaultCheckConfig
+	checkConfig.Timeout = 1
+	goCheck := NewGoCheckWithContext(&checkConfig, options, func(_ *types.Event) (int, error) {
+		return 0, nil
+	}, func(ctx context.Context, _ *types.Event) (int, error) {
+		<-ctx.Done()
+		return CheckStateOK, nil
+	}, false)
+	goCheck.cmd.SetArgs([]string{})
+
+	var exitStatus int
+	var errorStr string
+	goCheck.exitFunction = func(i int) {
+		exitStatus = i
+	}
+	goCheck.errorLogFunction = func(format string, a ...interface{}) {
+		errorStr = fmt.Sprintf(format, a...)
+	}
+	goCheck.Execute()
+
+	assert.Equal(t, CheckStateUnknown, exitStatus)
+	assert.Contains(t, errorStr, "timed out after 1s")
+}
+
+func TestGoCheck_Execute_Metrics(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+		status int
+	}{
+		{format: MetricFormatGraphite, want: "load 1.5 1600000000\n", status: CheckStateOK},
+		{format: MetricFormatNagios, want: "TestHandler | load=1.5\n", status: CheckStateOK},
+		{format: "invalid", want: "", status: CheckStateUnknown},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			values := &checkValues{}
+			options := getCheckOptions(values)
+			executed := false
+			goCheck := NewGoCheckWithMetrics(&defaultCheckConfig, options, func(_ *types.Event) (int, error) {
+				return 0, nil
+			}, func(_ context.Context, _ *types.Event) (int, []*types.MetricPoint, error) {
+				executed = true
+				point := NewMetricPoint("load", 1.5, nil)
+				point.Timestamp = 1600000000
+				return CheckStateOK, []*types.MetricPoint{point}, nil
+			}, false)
+			assert.Len(t, goCheck.options, len(options)+1)
+			goCheck.cmd.SetArgs([]string{"--metric-format", test.format})
+
+			out := new(bytes.Buffer)
+			goCheck.out = out
+			var exitStatus int
+			goCheck.exitFunction = func(i int) {
+				exitStatus = i
+			}
+			goCheck.errorLogFunction = func(format string, a ...interface{}) {}
+			goCheck.Execute()
+
+			assert.Equal(t, test.status, exitStatus)
+			assert.Equal(t, test.want, out.String())
+			assert.Equal(t, test.format != "invalid", executed)
+		})
+	}
+}
+
+func TestGoCheck_MetricFormatOption(t *testing.T) {
+	execute := func(_ context.Context, _ *types.Event) (int, []*types.MetricPoint, error) {
+		point := NewMetricPoint("load", 1.5, nil)
+		point.Timestamp = 1600000000
+		return CheckStateOK, []*types.MetricPoint{point}, nil
+	}
+	validate := func(_ *types.Event) (int, error) {
+		return 0, nil
+	}
+
+	// the option of the check replaces the standard one
+	var format string
+	options := []*PluginConfigOption{{
+		Value:    &format,
+		Argument: "metric-format",
+		Usage:    "Format of the metrics",
+	}}
+	goCheck := NewGoCheckWithMetrics(&defaultCheckConfig, options, validate, execute, false)
+	assert.Len(t, goCheck.options, 1)
+	goCheck.cmd.SetArgs([]string{"--metric-format", MetricFormatGraphite})
+	out := new(bytes.Buffer)
+	goCheck.out = out
+	var exitStatus int
+	goCheck.exitFunction = func(i int) {
+		exitStatus = i
+	}
+	goCheck.Execute()
+	assert.Equal(t, CheckStateOK, exitStatus)
+	assert.Equal(t, "load 1.5 1600000000\n", out.String())
+
+	// options defined more than once are reported instead of panicking
+	logs := new(bytes.Buffer)
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+	var count int
+	var name string
+	options = []*PluginConfigOption{
+		{Value: &count, Argument: "metric-format"},
+		{Value: &name, Argument: "name", Shorthand: "n"},
+		{Value: &name, Argument: "other-name", Shorthand: "n"},
+	}
+	_ = NewGoCheckWithMetrics(&defaultCheckConfig, options[:1], validate, execute, false)
+	assert.Contains(t, logs.String(), "option --metric-format is defined more than once")
+	_ = NewGoCheckWithMetrics(&defaultCheckConfig, options[1:], validate, execute, false)
+	assert.Contains(t, logs.String(), "option -n of --other-name is already used")
+}
+
+func TestGoCheck_Execute_Result(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate error
+		format   string
+		result   *CheckResult
+		err      error
+		want     string
+		status   int
+	}{
+		{
+			name:   "critical",
+			result: &CheckResult{Status: CheckStateCritical, Output: "down", LongOutput: []string{"details"}},
+			want:   "TestHandler CRITICAL: down\ndetails\n",
+			status: CheckStateCritical,
+		},
+		{
+			name:     "validation error",
+			validate: errors.New("bad input"),
+			want:     "TestHandler UNKNOWN: error validating input: bad input\n",
+			status:   CheckStateUnknown,
+		},
+		{
+			name:   "execution error",
+			result: &CheckResult{Status: CheckStateOK},
+			err:    errors.New("connection refused"),
+			want:   "TestHandler UNKNOWN: error executing check: connection refused\n",
+			status: CheckStateUnknown,
+		},
+		{
+			name:   "nil result",
+			want:   "TestHandler UNKNOWN: error executing check: no check result returned\n",
+			status: CheckStateUnknown,
+		},
+		{
+			name:   "status out of range",
+			result: &CheckResult{Status: 5, Output: "odd"},
+			want:   "TestHandler UNKNOWN: odd\n",
+			status: CheckStateUnknown,
+		},
+		{
+			name:   "invalid metric format",
+			format: "invalid",
+			want:   "TestHandler UNKNOWN: invalid metric format \"invalid\", must be one of graphite_plaintext, influxdb_line, opentsdb_line, nagios_perfdata, prometheus_text\n",
+			status: CheckStateUnknown,
+		},
+		{
+			name:   "negative status",
+			result: &CheckResult{Status: -1, Output: "odd"},
+			want:   "TestHandler UNKNOWN: odd\n",
+			status: CheckStateUnknown,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values := &checkValues{}
+			options := getCheckOptions(values)
+			goCheck := NewGoCheckWithResult(&defaultCheckConfig, options, func(_ *types.Event) (int, error) {
+				return CheckStateOK, test.validate
+			}, func(_ context.Context, _ *types.Event) (*CheckResult, error) {
+				if test.format != "" {
+					t.Error("check executed with an invalid metric format")
+				}
+				return test.result, test.err
+			}, false)
+			format := MetricFormatNagios
+			if test.format != "" {
+				format = test.format
+			}
+			goCheck.cmd.SetArgs([]string{"--metric-format", format})
+
+			out := new(bytes.Buffer)
+			goCheck.out = out
+			var exitStatus int
+			var errorStr string
+			goCheck.exitFunction = func(i int) {
+				exitStatus = i
+			}
+			goCheck.errorLogFunction = func(format string, a ...interface{}) {
+				errorStr = fmt.Sprintf(format, a...)
+			}
+			goCheck.Execute()
+
+			assert.Equal(t, test.status, exitStatus)
+			assert.Equal(t, test.want, out.String())
+			assert.Equal(t, "", errorStr)
+		})
+	}
+}
+
+func TestGoCheck_Execute_ResultTimeout(t *testing.T) {
+	checkConfig := defaultCheckConfig
+	checkConfig.Timeout = 1
+	goCheck := NewGoCheckWithResult(&checkConfig, nil, func(_ *types.Event) (int, error) {
+		return CheckStateOK, nil
+	}, func(ctx context.Context, _ *types.Event) (*CheckResult, error) {
+		<-ctx.Done()
+		// the result of an execution that timed out is never written
+		time.Sleep(100 * time.Millisecond)
+		return &CheckResult{Status: CheckStateOK, Output: "late"}, nil
+	}, false)
+	goCheck.cmd.SetArgs([]string{"--metric-format", MetricFormatNagios})
+
+	out := new(bytes.Buffer)
+	goCheck.out = out
+	var exitStatus int
+	var errorStr string
+	goCheck.exitFunction = func(i int) {
+		exitStatus = i
+	}
+	goCheck.errorLogFunction = func(format string, a ...interface{}) {
+		errorStr = fmt.Sprintf(format, a...)
+	}
+	goCheck.Execute()
+
+	assert.Equal(t, CheckStateUnknown, exitStatus)
+	assert.Equal(t, "TestHandler UNKNOWN: timed out after 1s\n", out.String())
+	assert.Equal(t, "", errorStr)
+}
+
 func getCheckOptions(values *checkValues) []*PluginConfigOption {
 	option1 := checkOption1
 	option2 := checkOption2
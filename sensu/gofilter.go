@@ -0,0 +1,88 @@
+package sensu
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+// A GoFilter reports its decision through its exit status, and writes the
+// matching word ("allow" or "deny") to stdout. Errors are written to stderr.
+const (
+	FilterStateAllow = 0
+	FilterStateDeny  = 1
+	FilterStateError = 2
+)
+
+type GoFilter struct {
+	basePlugin
+	validationFunction func(event *types.Event) error
+	executeFunction    func(ctx context.Context, event *types.Event) (bool, error)
+}
+
+// NewGoFilter creates a filter plugin. The execute function returns true if
+// the event should be allowed through the pipeline, and false if it should be
+// denied.
+func NewGoFilter(config *PluginConfig, options []*PluginConfigOption,
+	validationFunction func(event *types.Event) error,
+	executeFunction func(event *types.Event) (bool, error)) *GoFilter {
+	return NewGoFilterWithContext(config, options, validationFunction,
+		func(_ context.Context, event *types.Event) (bool, error) {
+			return executeFunction(event)
+		})
+}
+
+// NewGoFilterWithContext creates a filter whose execute function receives a
+// context. The context is cancelled when the filter exceeds the Timeout set
+// in its PluginConfig, in which case the filter exits with FilterStateError.
+func NewGoFilterWithContext(config *PluginConfig, options []*PluginConfigOption,
+	validationFunction func(event *types.Event) error,
+	executeFunction func(ctx context.Context, event *types.Event) (bool, error)) *GoFilter {
+	goFilter := &GoFilter{
+		basePlugin: basePlugin{
+			config:                 config,
+			options:                options,
+			sensuEvent:             nil,
+			eventReader:            os.Stdin,
+			readEvent:              true,
+			eventMandatory:         true,
+			eventValidation:        true,
+			configurationOverrides: true,
+			errorExitStatus:        FilterStateError,
+			timeoutExitStatus:      FilterStateError,
+			out:                    os.Stdout,
+		},
+		validationFunction: validationFunction,
+		executeFunction:    executeFunction,
+	}
+	goFilter.pluginWorkflowFunction = goFilter.goFilterWorkflow
+	if err := goFilter.initPlugin(); err != nil {
+		log.Printf("failed to initialize filter plugin: %s", err)
+	}
+	return goFilter
+}
+
+// Executes the filter's workflow
+func (goFilter *GoFilter) goFilterWorkflow(ctx context.Context, _ []string) (int, error) {
+	// Validate input using validateFunction
+	err := goFilter.validationFunction(goFilter.sensuEvent)
+	if err != nil {
+		return FilterStateError, fmt.Errorf("error validating input: %s", err)
+	}
+
+	// Execute filter logic using executeFunction
+	allow, err := goFilter.executeFunction(ctx, goFilter.sensuEvent)
+	if err != nil {
+		return FilterStateError, fmt.Errorf("error executing filter: %s", err)
+	}
+
+	if allow {
+		_, _ = fmt.Fprint(goFilter.out, "allow")
+		return FilterStateAllow, nil
+	}
+	_, _ = fmt.Fprint(goFilter.out, "deny")
+	return FilterStateDeny, nil
+}
@@ -0,0 +1,184 @@
+package sensu
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type filterValues struct {
+	arg1 string
+	arg2 uint64
+	arg3 bool
+}
+
+var (
+	defaultFilterConfig = PluginConfig{
+		Name:     "TestFilter",
+		Short:    "Short Description",
+		Timeout:  10,
+		Keyspace: "sensu.io/plugins/segp/config",
+	}
+
+	filterCmdLineArgs = []string{"--arg1", "value-arg1", "--arg2", "7531", "--arg3=false"}
+)
+
+func TestNewGoFilter(t *testing.T) {
+	values := &filterValues{}
+	options := getFilterOptions(values)
+	goFilter := NewGoFilter(&defaultFilterConfig, options, func(event *types.Event) error {
+		return nil
+	}, func(event *types.Event) (bool, error) {
+		return true, nil
+	})
+
+	assert.NotNil(t, goFilter)
+	assert.Equal(t, options, goFilter.options)
+	assert.Equal(t, &defaultFilterConfig, goFilter.config)
+	assert.NotNil(t, goFilter.validationFunction)
+	assert.NotNil(t, goFilter.executeFunction)
+	assert.Nil(t, goFilter.sensuEvent)
+	assert.Equal(t, os.Stdin, goFilter.eventReader)
+	assert.Equal(t, os.Stdout, goFilter.out)
+	assert.NotNil(t, goFilter.cmd)
+}
+
+func goFilterExecuteUtil(t *testing.T, filterConfig *PluginConfig, eventFile string, cmdLineArgs []string,
+	validationFunction func(*types.Event) error, executeFunction func(*types.Event) (bool, error),
+	expectedValue1 interface{}, expectedValue2 interface{}, expectedValue3 interface{}) (int, string, string) {
+
+	t.Helper()
+	values := filterValues{}
+	options := getFilterOptions(&values)
+
+	goFilter := NewGoFilter(filterConfig, options, validationFunction, executeFunction)
+	out := new(bytes.Buffer)
+	goFilter.out = out
+
+	if len(cmdLineArgs) > 0 {
+		goFilter.cmd.SetArgs(cmdLineArgs)
+	} else {
+		goFilter.cmd.SetArgs([]string{})
+	}
+
+	goFilter.cmd.SilenceErrors = true
+	goFilter.cmd.SilenceUsage = true
+
+	var exitStatus = -99
+	var errorStr = ""
+	goFilter.eventReader = getFileReader(eventFile)
+	goFilter.exitFunction = func(i int) {
+		exitStatus = i
+	}
+	goFilter.errorLogFunction = func(format string, a ...interface{}) {
+		errorStr = fmt.Sprintf(format, a...)
+	}
+	goFilter.Execute()
+
+	assert.Equal(t, expectedValue1, values.arg1)
+	assert.Equal(t, expectedValue2, values.arg2)
+	assert.Equal(t, expectedValue3, values.arg3)
+
+	return exitStatus, out.String(), errorStr
+}
+
+// Test allow decision with check override
+func TestGoFilter_Execute_Allow(t *testing.T) {
+	var validateCalled, executeCalled bool
+	clearEnvironment()
+	exitStatus, output, errorStr := goFilterExecuteUtil(t, &defaultFilterConfig, "test/event-check-override.json", nil,
+		func(event *types.Event) error {
+			validateCalled = true
+			assert.NotNil(t, event)
+			return nil
+		}, func(event *types.Event) (bool, error) {
+			executeCalled = true
+			assert.NotNil(t, event)
+			return true, nil
+		},
+		"value-check1", uint64(1357), false)
+	assert.Equal(t, "", errorStr)
+	assert.Equal(t, FilterStateAllow, exitStatus)
+	assert.Equal(t, "allow", output)
+	assert.True(t, validateCalled)
+	assert.True(t, executeCalled)
+}
+
+// Test deny decision with cmd line arguments
+func TestGoFilter_Execute_Deny(t *testing.T) {
+	clearEnvironment()
+	exitStatus, output, errorStr := goFilterExecuteUtil(t, &defaultFilterConfig, "test/event-no-override.json", filterCmdLineArgs,
+		func(event *types.Event) error {
+			return nil
+		}, func(event *types.Event) (bool, error) {
+			return false, nil
+		},
+		"value-arg1", uint64(7531), false)
+	assert.Equal(t, "", errorStr)
+	assert.Equal(t, FilterStateDeny, exitStatus)
+	assert.Equal(t, "deny", output)
+}
+
+// Test validation error
+func TestGoFilter_Execute_ValidationError(t *testing.T) {
+	var executeCalled bool
+	clearEnvironment()
+	exitStatus, output, errorStr := goFilterExecuteUtil(t, &defaultFilterConfig, "test/event-no-override.json", filterCmdLineArgs,
+		func(event *types.Event) error {
+			return fmt.Errorf("validation error")
+		}, func(event *types.Event) (bool, error) {
+			executeCalled = true
+			return true, nil
+		},
+		"value-arg1", uint64(7531), false)
+	assert.Equal(t, FilterStateError, exitStatus)
+	assert.Equal(t, "", output)
+	assert.Contains(t, errorStr, "error validating input: validation error")
+	assert.False(t, executeCalled)
+}
+
+// Test execute error
+func TestGoFilter_Execute_ExecuteError(t *testing.T) {
+	clearEnvironment()
+	exitStatus, output, errorStr := goFilterExecuteUtil(t, &defaultFilterConfig, "test/event-no-override.json", filterCmdLineArgs,
+		func(event *types.Event) error {
+			return nil
+		}, func(event *types.Event) (bool, error) {
+			return true, fmt.Errorf("execution error")
+		},
+		"value-arg1", uint64(7531), false)
+	assert.Equal(t, FilterStateError, exitStatus)
+	assert.Equal(t, "", output)
+	assert.Contains(t, errorStr, "error executing filter: execution error")
+}
+
+// Test invalid event - no entity
+func TestGoFilter_Execute_EventNoEntity(t *testing.T) {
+	var executeCalled bool
+	clearEnvironment()
+	exitStatus, _, errorStr := goFilterExecuteUtil(t, &defaultFilterConfig, "test/event-no-entity.json", filterCmdLineArgs,
+		func(event *types.Event) error {
+			return nil
+		}, func(event *types.Event) (bool, error) {
+			executeCalled = true
+			return true, nil
+		},
+		"value-arg1", uint64(7531), false)
+	assert.Equal(t, FilterStateError, exitStatus)
+	assert.Contains(t, errorStr, "event must contain an entity")
+	assert.False(t, executeCalled)
+}
+
+func getFilterOptions(values *filterValues) []*PluginConfigOption {
+	option1 := defaultOption1
+	option2 := defaultOption2
+	option3 := defaultOption3
+	option1.Value = &values.arg1
+	option2.Value = &values.arg2
+	option3.Value = &values.arg3
+	return []*PluginConfigOption{&option1, &option2, &option3}
+}
@@ -1,6 +1,7 @@
 package sensu
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -14,12 +15,21 @@ import (
 type GoHandler struct {
 	basePlugin
 	validationFunction func(event *types.Event) error
-	executeFunction    func(event *types.Event) error
+	executeFunction    func(ctx context.Context, event *types.Event) error
+	batchFunction      func(ctx context.Context, events []*types.Event) error
 	enterprise         bool
 }
 
 func NewGoHandler(config *PluginConfig, options []*PluginConfigOption,
 	validationFunction func(event *types.Event) error, executeFunction func(event *types.Event) error) *GoHandler {
+	return NewGoHandlerWithContext(config, options, validationFunction, withoutContext(executeFunction))
+}
+
+// NewGoHandlerWithContext creates a handler whose execute function receives a
+// context. The context is cancelled when the handler exceeds the Timeout set
+// in its PluginConfig, in which case the handler exits with an error status.
+func NewGoHandlerWithContext(config *PluginConfig, options []*PluginConfigOption,
+	validationFunction func(event *types.Event) error, executeFunction func(ctx context.Context, event *types.Event) error) *GoHandler {
 	goHandler := &GoHandler{
 		basePlugin: basePlugin{
 			config:                 config,
@@ -30,7 +40,9 @@ func NewGoHandler(config *PluginConfig, options []*PluginConfigOption,
 			eventMandatory:         true,
 			eventValidation:        true,
 			configurationOverrides: true,
+			batchEvents:            true,
 			errorExitStatus:        1,
+			timeoutExitStatus:      1,
 		},
 		validationFunction: validationFunction,
 		executeFunction:    executeFunction,
@@ -44,8 +56,31 @@ func NewGoHandler(config *PluginConfig, options []*PluginConfigOption,
 	return goHandler
 }
 
+// NewGoBatchHandler creates a handler whose execute function is called once
+// with all the events read from stdin, whether a single event, a JSON array
+// of events or newline delimited events was supplied. Each event is validated
+// with the validation function first. Configuration overrides are only
+// applied when a single event is supplied.
+func NewGoBatchHandler(config *PluginConfig, options []*PluginConfigOption,
+	validationFunction func(event *types.Event) error, executeFunction func(ctx context.Context, events []*types.Event) error) *GoHandler {
+	goHandler := NewGoHandlerWithContext(config, options, validationFunction,
+		func(ctx context.Context, event *types.Event) error {
+			return executeFunction(ctx, []*types.Event{event})
+		})
+	goHandler.batchFunction = executeFunction
+	goHandler.batchWorkflowFunction = goHandler.goHandlerBatchWorkflow
+	return goHandler
+}
+
 func NewEnterpriseGoHandler(config *PluginConfig, options []*PluginConfigOption,
 	validationFunction func(event *types.Event) error, executeFunction func(event *types.Event) error) *GoHandler {
+	return NewEnterpriseGoHandlerWithContext(config, options, validationFunction, withoutContext(executeFunction))
+}
+
+// NewEnterpriseGoHandlerWithContext is the context aware version of
+// NewEnterpriseGoHandler, see NewGoHandlerWithContext.
+func NewEnterpriseGoHandlerWithContext(config *PluginConfig, options []*PluginConfigOption,
+	validationFunction func(event *types.Event) error, executeFunction func(ctx context.Context, event *types.Event) error) *GoHandler {
 	goHandler := &GoHandler{
 		basePlugin: basePlugin{
 			config:                 config,
@@ -55,7 +90,9 @@ func NewEnterpriseGoHandler(config *PluginConfig, options []*PluginConfigOption,
 			readEvent:              true,
 			eventMandatory:         true,
 			configurationOverrides: true,
+			batchEvents:            true,
 			errorExitStatus:        1,
+			timeoutExitStatus:      1,
 		},
 		validationFunction: validationFunction,
 		executeFunction:    executeFunction,
@@ -71,22 +108,10 @@ func NewEnterpriseGoHandler(config *PluginConfig, options []*PluginConfigOption,
 }
 
 // Executes the handler's workflow
-func (goHandler *GoHandler) goHandlerWorkflow(_ []string) (int, error) {
+func (goHandler *GoHandler) goHandlerWorkflow(ctx context.Context, _ []string) (int, error) {
 	event := goHandler.sensuEvent
-	if goHandler.enterprise {
-		var licenseFile *licensing.LicenseFile
-		license := os.Getenv("SENSU_LICENSE_FILE")
-		if license == "" {
-			return 1, fmt.Errorf("valid sensu license is required to execute")
-		}
-		err := json.Unmarshal([]byte(license), &licenseFile)
-		if err != nil {
-			return 1, fmt.Errorf("error reading license file: %s", err)
-		}
-		err = licenseFile.Validate()
-		if err != nil {
-			return 1, fmt.Errorf("error validating license file: %s", err)
-		}
+	if err := goHandler.validateLicense(); err != nil {
+		return 1, err
 	}
 
 	// Validate input using validateFunction
@@ -96,10 +121,60 @@ func (goHandler *GoHandler) goHandlerWorkflow(_ []string) (int, error) {
 	}
 
 	// Execute handler logic using executeFunction
-	err = goHandler.executeFunction(event)
+	err = goHandler.executeFunction(ctx, event)
 	if err != nil {
 		return 1, fmt.Errorf("error executing handler: %s", err)
 	}
 
 	return 0, nil
 }
+
+// withoutContext adapts an execute function that does not take a context
+func withoutContext(executeFunction func(event *types.Event) error) func(context.Context, *types.Event) error {
+	return func(_ context.Context, event *types.Event) error {
+		return executeFunction(event)
+	}
+}
+
+// Executes the handler's workflow for a batch of events
+func (goHandler *GoHandler) goHandlerBatchWorkflow(ctx context.Context, _ []string) (int, error) {
+	events := goHandler.sensuEvents
+	if err := goHandler.validateLicense(); err != nil {
+		return 1, err
+	}
+
+	// Validate each event using validateFunction
+	for _, event := range events {
+		if err := goHandler.validationFunction(event); err != nil {
+			return 1, fmt.Errorf("error validating input for event %s: %s", EventKey(event), err)
+		}
+	}
+
+	// Execute handler logic using batchFunction
+	if err := goHandler.batchFunction(ctx, events); err != nil {
+		return 1, fmt.Errorf("error executing handler: %s", err)
+	}
+
+	return 0, nil
+}
+
+// validateLicense validates the Sensu license of enterprise handlers
+func (goHandler *GoHandler) validateLicense() error {
+	if !goHandler.enterprise {
+		return nil
+	}
+	var licenseFile *licensing.LicenseFile
+	license, _ := goHandler.lookupEnv("SENSU_LICENSE_FILE")
+	if license == "" {
+		return fmt.Errorf("valid sensu license is required to execute")
+	}
+	err := json.Unmarshal([]byte(license), &licenseFile)
+	if err != nil {
+		return fmt.Errorf("error reading license file: %s", err)
+	}
+	err = licenseFile.Validate()
+	if err != nil {
+		return fmt.Errorf("error validating license file: %s", err)
+	}
+	return nil
+}
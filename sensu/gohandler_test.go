@@ -1,6 +1,9 @@
 package sensu
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -373,6 +376,38 @@ func TestGoHandler_Execute_ExecuteError(t *testing.T) {
 	assert.True(t, executeCalled)
 }
 
+// Test timeout
+func TestGoHandler_Execute_Timeout(t *testing.T) {
+	clearEnvironment()
+	values := handlerValues{}
+	options := getHandlerOptions(&values)
+	handlerConfig := defaultHandlerConfig
+	handlerConfig.Timeout = 1
+
+	goHandler := NewGoHandlerWithContext(&handlerConfig, options,
+		func(event *types.Event) error {
+			return nil
+		}, func(ctx context.Context, event *types.Event) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	goHandler.cmd.SetArgs([]string{})
+
+	var exitStatus int
+	var errorStr string
+	goHandler.eventReader = getFileReader("test/event-no-override.json")
+	goHandler.exitFunction = func(i int) {
+		exitStatus = i
+	}
+	goHandler.errorLogFunction = func(format string, a ...interface{}) {
+		errorStr = fmt.Sprintf(format, a...)
+	}
+	goHandler.Execute()
+
+	assert.Equal(t, 1, exitStatus)
+	assert.Contains(t, errorStr, "timed out after 1s")
+}
+
 // Test invalid event - no timestamp
 func TestGoHandler_Execute_EventNoTimestamp(t *testing.T) {
 	var validateCalled, executeCalled bool
@@ -579,6 +614,68 @@ func getHandlerOptions(values *handlerValues) []*PluginConfigOption {
 	return []*PluginConfigOption{&option1, &option2, &option3}
 }
 
+// Test batch of events with per event configuration overrides
+func TestGoHandler_Execute_Batch(t *testing.T) {
+	clearEnvironment()
+	values := handlerValues{}
+	options := getHandlerOptions(&values)
+	var handled, arg1Values []string
+	goHandler := NewGoHandler(&defaultHandlerConfig, options,
+		func(event *types.Event) error {
+			return nil
+		}, func(event *types.Event) error {
+			handled = append(handled, event.Check.Name)
+			arg1Values = append(arg1Values, values.arg1)
+			if event.Check.Name == "check-2" {
+				return fmt.Errorf("execution error")
+			}
+			return nil
+		})
+
+	events := []*types.Event{
+		readTestEvent(t, "test/event-check-override.json", "check-1"),
+		readTestEvent(t, "test/event-no-override.json", "check-2"),
+		readTestEvent(t, "test/event-no-override.json", "check-3"),
+	}
+	stderr := new(bytes.Buffer)
+	exitStatus := goHandler.ExecuteWithEnvironment(Environment{
+		Stdin:  bytes.NewReader(marshalNDJSON(t, events)),
+		Stderr: stderr,
+	})
+
+	assert.Equal(t, 1, exitStatus)
+	assert.Equal(t, []string{"check-1", "check-2", "check-3"}, handled)
+	assert.Equal(t, []string{"value-check1", "Default1", "Default1"}, arg1Values)
+	assert.Contains(t, stderr.String(), "1 of 3 events failed: webserver01/check-2: error executing handler: execution error")
+}
+
+// Test batch handler
+func TestGoBatchHandler_Execute(t *testing.T) {
+	clearEnvironment()
+	values := handlerValues{}
+	options := getHandlerOptions(&values)
+	var batches [][]*types.Event
+	goHandler := NewGoBatchHandler(&defaultHandlerConfig, options,
+		func(event *types.Event) error {
+			return nil
+		}, func(ctx context.Context, events []*types.Event) error {
+			batches = append(batches, events)
+			return nil
+		})
+
+	event := readTestEvent(t, "test/event-no-override.json", "check-1")
+	events, _ := json.Marshal([]*types.Event{event, event})
+	exitStatus := goHandler.ExecuteWithEnvironment(Environment{Stdin: bytes.NewReader(events)})
+	assert.Equal(t, 0, exitStatus)
+
+	exitStatus = goHandler.ExecuteWithEnvironment(Environment{Stdin: bytes.NewReader(marshalNDJSON(t, []*types.Event{event}))})
+	assert.Equal(t, 0, exitStatus)
+
+	assert.Len(t, batches, 2)
+	assert.Len(t, batches[0], 2)
+	assert.Len(t, batches[1], 1)
+}
+
 func TestNewGoHandlerEnterprise(t *testing.T) {
 	var exitStatus int
 	values := &handlerValues{}
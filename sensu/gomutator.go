@@ -1,9 +1,9 @@
 package sensu
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"os"
 
@@ -12,14 +12,30 @@ import (
 
 type GoMutator struct {
 	basePlugin
-	out                io.Writer
 	validationFunction func(event *types.Event) error
-	executeFunction    func(event *types.Event) (*types.Event, error)
+	executeFunction    func(ctx context.Context, event *types.Event) (*types.Event, error)
 }
 
+// NewGoMutator creates a mutator plugin. Mutators also accept a JSON array of
+// events or newline delimited events on stdin. The execute function is then
+// called for each event, and the mutated events are written to stdout as
+// newline delimited JSON.
 func NewGoMutator(config *PluginConfig, options []*PluginConfigOption,
 	validationFunction func(event *types.Event) error,
 	executeFunction func(event *types.Event) (*types.Event, error)) *GoMutator {
+	return NewGoMutatorWithContext(config, options, validationFunction,
+		func(_ context.Context, event *types.Event) (*types.Event, error) {
+			return executeFunction(event)
+		})
+}
+
+// NewGoMutatorWithContext creates a mutator whose execute function receives a
+// context. The context is cancelled when the mutator exceeds the Timeout set
+// in its PluginConfig, in which case the mutator exits with an error status
+// and writes nothing to stdout.
+func NewGoMutatorWithContext(config *PluginConfig, options []*PluginConfigOption,
+	validationFunction func(event *types.Event) error,
+	executeFunction func(ctx context.Context, event *types.Event) (*types.Event, error)) *GoMutator {
 	goMutator := &GoMutator{
 		basePlugin: basePlugin{
 			config:                 config,
@@ -30,10 +46,11 @@ func NewGoMutator(config *PluginConfig, options []*PluginConfigOption,
 			eventMandatory:         true,
 			eventValidation:        true,
 			configurationOverrides: true,
-			exitFunction:           os.Exit,
+			batchEvents:            true,
 			errorExitStatus:        1,
+			timeoutExitStatus:      1,
+			out:                    os.Stdout,
 		},
-		out:                os.Stdout,
 		validationFunction: validationFunction,
 		executeFunction:    executeFunction,
 	}
@@ -45,7 +62,7 @@ func NewGoMutator(config *PluginConfig, options []*PluginConfigOption,
 }
 
 // Executes the handler's workflow
-func (goMutator *GoMutator) goMutatorWorkflow(_ []string) (int, error) {
+func (goMutator *GoMutator) goMutatorWorkflow(ctx context.Context, _ []string) (int, error) {
 	// Validate input using validateFunction
 	err := goMutator.validationFunction(goMutator.sensuEvent)
 	if err != nil {
@@ -53,11 +70,16 @@ func (goMutator *GoMutator) goMutatorWorkflow(_ []string) (int, error) {
 	}
 
 	// Execute handler logic using executeFunction
-	event, err := goMutator.executeFunction(goMutator.sensuEvent)
+	event, err := goMutator.executeFunction(ctx, goMutator.sensuEvent)
 	if err != nil {
 		return 1, fmt.Errorf("error executing mutator: %s", err)
 	}
 
+	// Do not write a partial result once the deadline has passed
+	if err := ctx.Err(); err != nil {
+		return 1, err
+	}
+
 	if event != nil {
 		eventBytes, err := json.Marshal(event)
 		if err != nil {
@@ -68,6 +90,9 @@ func (goMutator *GoMutator) goMutatorWorkflow(_ []string) (int, error) {
 	} else {
 		_, _ = fmt.Fprint(goMutator.out, "{}")
 	}
+	if len(goMutator.sensuEvents) > 0 {
+		_, _ = fmt.Fprintln(goMutator.out)
+	}
 
 	return 0, err
 }
@@ -608,6 +608,35 @@ func TestGoMutator_Execute_NoKeyspace(t *testing.T) {
 	assert.True(t, executeCalled)
 }
 
+// Test batch of events
+func TestGoMutator_Execute_Batch(t *testing.T) {
+	clearEnvironment()
+	options := getMutatorVales(&mutatorValues{})
+	goMutator := NewGoMutator(&defaultMutatorConfig, options,
+		func(event *types.Event) error {
+			return nil
+		}, func(event *types.Event) (*types.Event, error) {
+			event.Check.Output = "mutated " + event.Check.Name
+			return event, nil
+		})
+
+	events := []*types.Event{
+		readTestEvent(t, "test/event-no-override.json", "check-1"),
+		readTestEvent(t, "test/event-no-override.json", "check-2"),
+	}
+	stdout := new(bytes.Buffer)
+	exitStatus := goMutator.ExecuteWithEnvironment(Environment{
+		Stdin:  bytes.NewReader(marshalNDJSON(t, events)),
+		Stdout: stdout,
+	})
+	assert.Equal(t, 0, exitStatus)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "mutated check-1")
+	assert.Contains(t, lines[1], "mutated check-2")
+}
+
 func getMutatorVales(values *mutatorValues) []*PluginConfigOption {
 	option1 := mutatorOption1
 	option2 := mutatorOption2
@@ -1,6 +1,8 @@
 package sensu
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,10 +13,13 @@ import (
 	"path"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sensu/sensu-go/types"
 	"github.com/sensu/sensu-plugin-sdk/version"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -58,9 +63,14 @@ type PluginConfigOption struct {
 
 // PluginConfig defines the base plugin configuration.
 type PluginConfig struct {
-	Name     string
-	Short    string
-	Timeout  uint64
+	Name  string
+	Short string
+
+	// Timeout is the maximum number of seconds the plugin is allowed to run.
+	// When the timeout expires the context handed to the execute function is
+	// cancelled and the plugin exits. A value of 0 disables the timeout.
+	Timeout uint64
+
 	Keyspace string
 }
 
@@ -69,8 +79,11 @@ type basePlugin struct {
 	config                 *PluginConfig
 	options                []*PluginConfigOption
 	sensuEvent             *types.Event
+	sensuEvents            []*types.Event
 	eventReader            io.Reader
-	pluginWorkflowFunction func([]string) (int, error)
+	pluginWorkflowFunction func(context.Context, []string) (int, error)
+	batchWorkflowFunction  func(context.Context, []string) (int, error)
+	batchEvents            bool
 	cmd                    *cobra.Command
 	readEvent              bool
 	eventMandatory         bool
@@ -78,8 +91,19 @@ type basePlugin struct {
 	configurationOverrides bool
 	exitStatus             int
 	errorExitStatus        int
-	exitFunction           func(int)
-	errorLogFunction       func(format string, a ...interface{})
+	timeoutExitStatus      int
+	// timeoutFunction, if set, reports the timeout of the workflow run with
+	// ctx instead of the error log, and returns false if the workflow
+	// completed before it could report it.
+	timeoutFunction  func(ctx context.Context, err error) bool
+	exitFunction     func(int)
+	errorLogFunction func(format string, a ...interface{})
+	out              io.Writer
+	viper            *viper.Viper
+	lookupEnv        func(key string) (string, bool)
+	// workflows tracks the workflow goroutines, which keep running after
+	// they time out.
+	workflows sync.WaitGroup
 }
 
 func (goPlugin *basePlugin) readSensuEvent() error {
@@ -93,6 +117,10 @@ func (goPlugin *basePlugin) readSensuEvent() error {
 		}
 	}
 
+	if goPlugin.batchEvents {
+		return goPlugin.readSensuEvents(eventJSON)
+	}
+
 	sensuEvent := &types.Event{}
 	err = json.Unmarshal(eventJSON, sensuEvent)
 	if err != nil {
@@ -108,21 +136,80 @@ func (goPlugin *basePlugin) readSensuEvent() error {
 	return nil
 }
 
+// readSensuEvents decodes a single event, a JSON array of events or a stream of newline delimited events. A single
+// event is stored in sensuEvent, multiple events in sensuEvents.
+func (goPlugin *basePlugin) readSensuEvents(eventJSON []byte) error {
+	var sensuEvents []*types.Event
+	if trimmed := bytes.TrimSpace(eventJSON); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &sensuEvents); err != nil {
+			return fmt.Errorf("Failed to unmarshal STDIN data: %s", err)
+		}
+		if len(sensuEvents) == 0 {
+			return errors.New("Failed to unmarshal STDIN data: no events")
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		for len(sensuEvents) == 0 || decoder.More() {
+			sensuEvent := &types.Event{}
+			if err := decoder.Decode(sensuEvent); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return fmt.Errorf("Failed to unmarshal STDIN data: %s", err)
+			}
+			sensuEvents = append(sensuEvents, sensuEvent)
+		}
+	}
+
+	for i, sensuEvent := range sensuEvents {
+		if sensuEvent == nil {
+			return fmt.Errorf("Failed to unmarshal STDIN data: event %d is null", i)
+		}
+		if goPlugin.eventValidation {
+			if err := validateEvent(sensuEvent); err != nil {
+				if len(sensuEvents) > 1 {
+					return fmt.Errorf("event %d: %s", i, err)
+				}
+				return err
+			}
+		}
+	}
+
+	if len(sensuEvents) == 1 {
+		goPlugin.sensuEvent = sensuEvents[0]
+		return nil
+	}
+	goPlugin.sensuEvents = sensuEvents
+	return nil
+}
+
 func (p *basePlugin) initPlugin() error {
+	// Each plugin uses its own viper instance so that multiple plugins can be set up in the same process
+	p.viper = viper.New()
 	p.cmd = &cobra.Command{
 		Use:           p.config.Name,
 		Short:         p.config.Short,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			if err := p.viper.BindPFlags(cmd.Flags()); err != nil {
 				return err
 			}
 			return p.cobraExecuteFunction(args)
 		},
 	}
-	p.exitFunction = os.Exit
-	p.errorLogFunction = func(format string, a ...interface{}) {
-		_, _ = fmt.Fprintf(os.Stderr, format, a...)
+	if p.exitFunction == nil {
+		p.exitFunction = os.Exit
+	}
+	if p.errorLogFunction == nil {
+		p.errorLogFunction = func(format string, a ...interface{}) {
+			_, _ = fmt.Fprintf(os.Stderr, format, a...)
+		}
+	}
+	if p.lookupEnv == nil {
+		p.lookupEnv = os.LookupEnv
+	}
+	if p.out == nil {
+		p.out = os.Stdout
 	}
 
 	p.cmd.AddCommand(&cobra.Command{
@@ -130,7 +217,7 @@ func (p *basePlugin) initPlugin() error {
 		Short:         "Print the version number of this plugin",
 		SilenceErrors: true,
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println(version.Version())
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), version.Version())
 		},
 	})
 
@@ -139,20 +226,22 @@ func (p *basePlugin) initPlugin() error {
 
 func (p *basePlugin) setupFlags(cmd *cobra.Command) error {
 	for _, opt := range p.options {
-		if err := setupFlag(cmd, opt); err != nil {
+		if err := p.setupFlag(cmd, opt); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func setupFlag(cmd *cobra.Command, opt *PluginConfigOption) error {
+func (p *basePlugin) setupFlag(cmd *cobra.Command, opt *PluginConfigOption) error {
 	if len(opt.Argument) == 0 {
 		return nil
 	}
-	err := viper.BindEnv(opt.Argument, opt.Env)
-	if err != nil {
-		return err
+	if cmd.Flags().Lookup(opt.Argument) != nil {
+		return fmt.Errorf("option --%s is defined more than once", opt.Argument)
+	}
+	if len(opt.Shorthand) == 1 && cmd.Flags().ShorthandLookup(opt.Shorthand) != nil {
+		return fmt.Errorf("option -%s of --%s is already used", opt.Shorthand, opt.Argument)
 	}
 	if opt.Value == nil {
 		return errors.New("nil Value")
@@ -160,6 +249,9 @@ func setupFlag(cmd *cobra.Command, opt *PluginConfigOption) error {
 	if reflect.TypeOf(opt.Value).Kind() != reflect.Ptr {
 		return errors.New("Value is not a pointer")
 	}
+	if flagValue, ok := opt.Value.(pflag.Value); ok {
+		return p.setupValueFlag(cmd, opt, flagValue)
+	}
 	value := reflect.Indirect(reflect.ValueOf(opt.Value))
 	if opt.Default != nil {
 		defaultType := reflect.TypeOf(opt.Default)
@@ -167,41 +259,42 @@ func setupFlag(cmd *cobra.Command, opt *PluginConfigOption) error {
 		if t1, t2 := valueType.Kind(), defaultType.Kind(); t1 != t2 {
 			return fmt.Errorf("Value type does not match Default type: %v != %v", t1, t2)
 		}
-		viper.SetDefault(opt.Argument, opt.Default)
+		p.viper.SetDefault(opt.Argument, opt.Default)
 	}
+	p.setEnvDefault(opt)
 	switch kind := value.Type().Kind(); kind {
 	case reflect.Bool:
-		cmd.Flags().BoolVarP(opt.Value.(*bool), opt.Argument, opt.Shorthand, viper.GetBool(opt.Argument), opt.Usage)
+		cmd.Flags().BoolVarP(opt.Value.(*bool), opt.Argument, opt.Shorthand, p.viper.GetBool(opt.Argument), opt.Usage)
 	case reflect.Int:
-		cmd.Flags().IntVarP(opt.Value.(*int), opt.Argument, opt.Shorthand, viper.GetInt(opt.Argument), opt.Usage)
+		cmd.Flags().IntVarP(opt.Value.(*int), opt.Argument, opt.Shorthand, p.viper.GetInt(opt.Argument), opt.Usage)
 	case reflect.Int32:
-		cmd.Flags().Int32VarP(opt.Value.(*int32), opt.Argument, opt.Shorthand, viper.GetInt32(opt.Argument), opt.Usage)
+		cmd.Flags().Int32VarP(opt.Value.(*int32), opt.Argument, opt.Shorthand, p.viper.GetInt32(opt.Argument), opt.Usage)
 	case reflect.Int64:
-		cmd.Flags().Int64VarP(opt.Value.(*int64), opt.Argument, opt.Shorthand, viper.GetInt64(opt.Argument), opt.Usage)
+		cmd.Flags().Int64VarP(opt.Value.(*int64), opt.Argument, opt.Shorthand, p.viper.GetInt64(opt.Argument), opt.Usage)
 	case reflect.Uint:
-		cmd.Flags().UintVarP(opt.Value.(*uint), opt.Argument, opt.Shorthand, viper.GetUint(opt.Argument), opt.Usage)
+		cmd.Flags().UintVarP(opt.Value.(*uint), opt.Argument, opt.Shorthand, p.viper.GetUint(opt.Argument), opt.Usage)
 	case reflect.Uint32:
-		cmd.Flags().Uint32VarP(opt.Value.(*uint32), opt.Argument, opt.Shorthand, viper.GetUint32(opt.Argument), opt.Usage)
+		cmd.Flags().Uint32VarP(opt.Value.(*uint32), opt.Argument, opt.Shorthand, p.viper.GetUint32(opt.Argument), opt.Usage)
 	case reflect.Uint64:
-		cmd.Flags().Uint64VarP(opt.Value.(*uint64), opt.Argument, opt.Shorthand, viper.GetUint64(opt.Argument), opt.Usage)
+		cmd.Flags().Uint64VarP(opt.Value.(*uint64), opt.Argument, opt.Shorthand, p.viper.GetUint64(opt.Argument), opt.Usage)
 	case reflect.Float32:
-		cmd.Flags().Float32VarP(opt.Value.(*float32), opt.Argument, opt.Shorthand, float32(viper.GetFloat64(opt.Argument)), opt.Usage)
+		cmd.Flags().Float32VarP(opt.Value.(*float32), opt.Argument, opt.Shorthand, float32(p.viper.GetFloat64(opt.Argument)), opt.Usage)
 	case reflect.Float64:
-		cmd.Flags().Float64VarP(opt.Value.(*float64), opt.Argument, opt.Shorthand, viper.GetFloat64(opt.Argument), opt.Usage)
+		cmd.Flags().Float64VarP(opt.Value.(*float64), opt.Argument, opt.Shorthand, p.viper.GetFloat64(opt.Argument), opt.Usage)
 	case reflect.Map:
 		ptr, ok := opt.Value.(*map[string]string)
 		if !ok {
 			return fmt.Errorf("only pointer to map[string]string is allowed, not %v", kind)
 		}
-		cmd.Flags().StringToStringVarP(ptr, opt.Argument, opt.Shorthand, viper.GetStringMapString(opt.Argument), opt.Usage)
+		cmd.Flags().StringToStringVarP(ptr, opt.Argument, opt.Shorthand, p.viper.GetStringMapString(opt.Argument), opt.Usage)
 	case reflect.Slice:
 		ptr, ok := opt.Value.(*[]string)
 		if !ok {
 			return fmt.Errorf("only pointer to []string is allowed, not %v", kind)
 		}
-		cmd.Flags().StringSliceVarP(ptr, opt.Argument, opt.Shorthand, viper.GetStringSlice(opt.Argument), opt.Usage)
+		cmd.Flags().StringSliceVarP(ptr, opt.Argument, opt.Shorthand, p.viper.GetStringSlice(opt.Argument), opt.Usage)
 	case reflect.String:
-		cmd.Flags().StringVarP(opt.Value.(*string), opt.Argument, opt.Shorthand, viper.GetString(opt.Argument), opt.Usage)
+		cmd.Flags().StringVarP(opt.Value.(*string), opt.Argument, opt.Shorthand, p.viper.GetString(opt.Argument), opt.Usage)
 	default:
 		return fmt.Errorf("invalid input type: %v", kind)
 	}
@@ -214,31 +307,206 @@ func setupFlag(cmd *cobra.Command, opt *PluginConfigOption) error {
 	return nil
 }
 
-// cobraExecuteFunction is called by the argument's execute. The configuration overrides will be processed if necessary
-// and the pluginWorkflowFunction function executed
+// setupValueFlag sets up a flag for an option whose Value implements pflag.Value, such as *Range.
+// The Default of such an option, if any, must be a string.
+func (p *basePlugin) setupValueFlag(cmd *cobra.Command, opt *PluginConfigOption, value pflag.Value) error {
+	if opt.Default != nil {
+		if _, ok := opt.Default.(string); !ok {
+			return fmt.Errorf("Default of a %s option must be a string", value.Type())
+		}
+		p.viper.SetDefault(opt.Argument, opt.Default)
+	}
+	p.setEnvDefault(opt)
+	// unlike the typed flags, VarP keeps the current value: reset it so that
+	// the values of a previous execution don't leak into this one
+	ptr := reflect.ValueOf(opt.Value)
+	ptr.Elem().Set(reflect.Zero(ptr.Elem().Type()))
+	if defaultValue := p.viper.GetString(opt.Argument); len(defaultValue) > 0 {
+		if err := value.Set(defaultValue); err != nil {
+			return err
+		}
+	}
+	cmd.Flags().VarP(value, opt.Argument, opt.Shorthand, opt.Usage)
+	// Set empty DefValue string if option is a secret
+	if opt.Secret {
+		cmd.Flags().Lookup(opt.Argument).DefValue = ""
+	}
+	return nil
+}
+
+// setEnvDefault makes the value of the option's environment variable, if set, take precedence over the option's
+// Default. Command line arguments still take precedence over both.
+func (p *basePlugin) setEnvDefault(opt *PluginConfigOption) {
+	if len(opt.Env) == 0 {
+		return
+	}
+	if value, ok := p.lookupEnv(opt.Env); ok && len(value) > 0 {
+		p.viper.SetDefault(opt.Argument, value)
+	}
+}
+
+// cobraExecuteFunction is called by the argument's execute. The plugin workflow is run with a context that
+// expires after the configured timeout, if any. When the timeout expires the plugin returns immediately with its
+// timeout exit status.
 func (p *basePlugin) cobraExecuteFunction(args []string) error {
+	ctx, cancel := p.newContext()
+	defer cancel()
+
+	type workflowResult struct {
+		status int
+		err    error
+	}
+	done := make(chan workflowResult, 1)
+	p.workflows.Add(1)
+	go func() {
+		defer p.workflows.Done()
+		status, err := p.runWorkflow(ctx, args)
+		done <- workflowResult{status: status, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		// the workflow may complete as the timeout expires, without writing
+		// its result
+		if ctx.Err() != nil && p.timeoutFunction != nil &&
+			p.timeoutFunction(ctx, fmt.Errorf("timed out after %ds", p.config.Timeout)) {
+			p.exitStatus = p.timeoutExitStatus
+			return nil
+		}
+		p.exitStatus = result.status
+		return result.err
+	case <-ctx.Done():
+		err := fmt.Errorf("timed out after %ds", p.config.Timeout)
+		if p.timeoutFunction == nil {
+			p.exitStatus = p.timeoutExitStatus
+			return err
+		}
+		if p.timeoutFunction(ctx, err) {
+			p.exitStatus = p.timeoutExitStatus
+			return nil
+		}
+		result := <-done
+		p.exitStatus = result.status
+		return result.err
+	}
+}
+
+// newContext returns the context the plugin workflow runs with, bounded by the configured timeout.
+func (p *basePlugin) newContext() (context.Context, context.CancelFunc) {
+	if p.config.Timeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), time.Duration(p.config.Timeout)*time.Second)
+}
+
+// runWorkflow reads the event and processes the configuration overrides if necessary, then executes the
+// pluginWorkflowFunction function
+func (p *basePlugin) runWorkflow(ctx context.Context, args []string) (int, error) {
 	// Read the Sensu event if required
 	if p.readEvent {
 		err := p.readSensuEvent()
 		if err != nil {
-			p.exitStatus = p.errorExitStatus
-			return err
+			return p.errorExitStatus, err
 		}
 	}
 
+	if len(p.sensuEvents) > 0 {
+		return p.runBatchWorkflow(ctx, args)
+	}
+
+	return p.runEventWorkflow(ctx, args)
+}
+
+// runEventWorkflow processes the configuration overrides for the current event if necessary, then executes the
+// pluginWorkflowFunction function
+func (p *basePlugin) runEventWorkflow(ctx context.Context, args []string) (int, error) {
 	// If there is an event process configuration overrides if necessary
 	if p.sensuEvent != nil && p.configurationOverrides {
 		err := configurationOverrides(p.config, p.options, p.sensuEvent)
 		if err != nil {
-			p.exitStatus = p.errorExitStatus
-			return err
+			return p.errorExitStatus, err
 		}
 	}
 
-	exitStatus, err := p.pluginWorkflowFunction(args)
-	p.exitStatus = exitStatus
+	return p.pluginWorkflowFunction(ctx, args)
+}
+
+// runBatchWorkflow executes the batchWorkflowFunction function if set. Otherwise the pluginWorkflowFunction function
+// is executed for each event of the batch, with the configuration overrides of that event. Failures are aggregated
+// into a single error, and the highest exit status is returned.
+func (p *basePlugin) runBatchWorkflow(ctx context.Context, args []string) (int, error) {
+	if p.batchWorkflowFunction != nil {
+		return p.batchWorkflowFunction(ctx, args)
+	}
+
+	restoreOptions := p.snapshotOptions()
+	exitStatus := 0
+	var failures []string
+	for _, event := range p.sensuEvents {
+		if err := ctx.Err(); err != nil {
+			return p.timeoutExitStatus, err
+		}
+		restoreOptions()
+		p.sensuEvent = event
+		status, err := p.runEventWorkflow(ctx, args)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", EventKey(event), err))
+			if status == 0 {
+				status = p.errorExitStatus
+			}
+		}
+		if status > exitStatus {
+			exitStatus = status
+		}
+	}
+	p.sensuEvent = nil
 
-	return err
+	if len(failures) > 0 {
+		return exitStatus, fmt.Errorf("%d of %d events failed: %s", len(failures), len(p.sensuEvents),
+			strings.Join(failures, "; "))
+	}
+	return exitStatus, nil
+}
+
+// snapshotOptions saves the current option values, and returns a function restoring them. Slices and maps are
+// copied since overrides may modify them in place.
+func (p *basePlugin) snapshotOptions() func() {
+	saved := make([]reflect.Value, len(p.options))
+	for i, opt := range p.options {
+		if opt.Value == nil || reflect.TypeOf(opt.Value).Kind() != reflect.Ptr {
+			continue
+		}
+		saved[i] = copyValue(reflect.ValueOf(opt.Value).Elem())
+	}
+	return func() {
+		for i, opt := range p.options {
+			if saved[i].IsValid() {
+				reflect.ValueOf(opt.Value).Elem().Set(copyValue(saved[i]))
+			}
+		}
+	}
+}
+
+func copyValue(value reflect.Value) reflect.Value {
+	copied := reflect.New(value.Type()).Elem()
+	switch value.Kind() {
+	case reflect.Slice:
+		if !value.IsNil() {
+			copied.Set(reflect.MakeSlice(value.Type(), value.Len(), value.Len()))
+			reflect.Copy(copied, value)
+		}
+	case reflect.Map:
+		if !value.IsNil() {
+			copied.Set(reflect.MakeMapWithSize(value.Type(), value.Len()))
+			iter := value.MapRange()
+			for iter.Next() {
+				copied.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+	default:
+		copied.Set(value)
+	}
+	return copied
 }
 
 func (p *basePlugin) Execute() {
@@ -255,6 +523,80 @@ func (p *basePlugin) Execute() {
 	p.exitFunction(p.exitStatus)
 }
 
+// Environment is the process environment a plugin is executed in by ExecuteWithEnvironment.
+type Environment struct {
+	// Args are the command line arguments, without the program name.
+	Args []string
+
+	// Env holds the environment variables. If Env is nil, the environment of
+	// the process is used.
+	Env map[string]string
+
+	// Stdin is read for the Sensu event. A nil Stdin is empty.
+	Stdin io.Reader
+
+	// Stdout and Stderr receive the output of the plugin. Output written by
+	// the plugin's functions directly to os.Stdout or os.Stderr is not
+	// captured. Nil writers discard the output.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// ExecuteWithEnvironment executes the plugin in the given environment instead of the process' one, and returns its
+// exit status instead of exiting. The plugin's options are set up again, so the plugin can be executed any number
+// of times. It is mostly useful for testing plugins, see the sensutest package.
+//
+// When the plugin times out, ExecuteWithEnvironment still waits for the workflow to return before returning, so that
+// it doesn't run concurrently with the next execution: the execute function must return once its context is done.
+func (p *basePlugin) ExecuteWithEnvironment(env Environment) int {
+	p.workflows.Wait()
+	defer p.workflows.Wait()
+
+	stdout, stderr := env.Stdout, env.Stderr
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+	p.eventReader = env.Stdin
+	if p.eventReader == nil {
+		p.eventReader = strings.NewReader("")
+	}
+	p.out = stdout
+	p.errorLogFunction = func(format string, a ...interface{}) {
+		_, _ = fmt.Fprintf(stderr, format, a...)
+	}
+	p.lookupEnv = os.LookupEnv
+	if env.Env != nil {
+		p.lookupEnv = func(key string) (string, bool) {
+			value, ok := env.Env[key]
+			return value, ok
+		}
+	}
+	exitStatus := p.errorExitStatus
+	p.exitFunction = func(status int) {
+		exitStatus = status
+	}
+	p.sensuEvent = nil
+	p.sensuEvents = nil
+	p.exitStatus = 0
+
+	if err := p.initPlugin(); err != nil {
+		p.errorLogFunction("failed to initialize plugin: %s\n", err)
+	}
+	args := env.Args
+	if args == nil {
+		args = []string{}
+	}
+	p.cmd.SetArgs(args)
+	p.cmd.SetOut(stdout)
+	p.cmd.SetErr(stderr)
+
+	p.Execute()
+	return exitStatus
+}
+
 func validateEvent(event *types.Event) error {
 	if event.Timestamp <= 0 {
 		return errors.New("timestamp is missing or must be greater than zero")
@@ -264,6 +606,9 @@ func validateEvent(event *types.Event) error {
 }
 
 func setOptionValue(opt *PluginConfigOption, valueStr string) error {
+	if flagValue, ok := opt.Value.(pflag.Value); ok {
+		return flagValue.Set(valueStr)
+	}
 	optVal := reflect.Indirect(reflect.ValueOf(opt.Value))
 	if typ := optVal.Type(); typ.Kind() == reflect.Slice {
 		if err := json.Unmarshal([]byte(valueStr), &opt.Value); err == nil {
@@ -288,29 +633,45 @@ func configurationOverrides(config *PluginConfig, options []*PluginConfigOption,
 	}
 	for _, opt := range options {
 		if len(opt.Path) > 0 {
-			// compile the Annotation keyspace to look for configuration overrides
-			key := path.Join(config.Keyspace, opt.Path)
-			downcase := strings.ToLower(key)
-			keys := []string{downcase, key}
-			for _, key := range keys {
-				switch {
-				case event.Check != nil && len(event.Check.Annotations[key]) > 0:
-					err := setOptionValue(opt, event.Check.Annotations[key])
-					if err != nil {
-						return err
-					}
-					log.Printf("Overriding default handler configuration with value of \"Check.Annotations.%s\" (\"%s\")\n",
-						key, event.Check.Annotations[key])
-				case event.Entity != nil && len(event.Entity.Annotations[key]) > 0:
-					err := setOptionValue(opt, event.Entity.Annotations[key])
-					if err != nil {
-						return err
-					}
-					log.Printf("Overriding default handler configuration with value of \"Entity.Annotations.%s\" (\"%s\")\n",
-						key, event.Entity.Annotations[key])
-				}
+			value, field, ok := annotationOverride(event, config.Keyspace, opt.Path)
+			if !ok {
+				continue
+			}
+			if err := setOptionValue(opt, value); err != nil {
+				return err
 			}
+			log.Printf("Overriding default handler configuration with value of \"%s\" (\"%s\")\n",
+				field, value)
 		}
 	}
 	return nil
 }
+
+// AnnotationOverride returns the value of the check or entity annotation
+// overriding the configuration option with the given path, in keyspace. The
+// annotations of the check take precedence over the annotations of the entity.
+func AnnotationOverride(event *types.Event, keyspace, optPath string) (string, bool) {
+	value, _, ok := annotationOverride(event, keyspace, optPath)
+	return value, ok
+}
+
+// annotationOverride returns the value of the annotation overriding the
+// option with the given path, and the event field it was found in.
+func annotationOverride(event *types.Event, keyspace, optPath string) (value, field string, ok bool) {
+	if event == nil || keyspace == "" {
+		return "", "", false
+	}
+	// compile the Annotation keyspace to look for configuration overrides
+	key := path.Join(keyspace, optPath)
+	downcase := strings.ToLower(key)
+	keys := []string{downcase, key}
+	for _, key := range keys {
+		switch {
+		case event.Check != nil && len(event.Check.Annotations[key]) > 0:
+			value, field, ok = event.Check.Annotations[key], "Check.Annotations."+key, true
+		case event.Entity != nil && len(event.Entity.Annotations[key]) > 0:
+			value, field, ok = event.Entity.Annotations[key], "Entity.Annotations."+key, true
+		}
+	}
+	return value, field, ok
+}
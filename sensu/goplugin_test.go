@@ -1,10 +1,15 @@
 package sensu
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
+	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/sensu/sensu-go/types"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -126,3 +131,114 @@ func clearEnvironment() {
 	_ = os.Unsetenv("ENV_2")
 	_ = os.Unsetenv("ENV_3")
 }
+
+func TestReadSensuEvents(t *testing.T) {
+	events := []*types.Event{readTestEvent(t, "test/event-no-override.json", "check-1"),
+		readTestEvent(t, "test/event-no-override.json", "check-2")}
+	array, _ := json.Marshal(events)
+	tests := map[string][]byte{
+		"single": marshalNDJSON(t, events[:1]),
+		"array":  array,
+		"ndjson": marshalNDJSON(t, events),
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			plugin := &basePlugin{batchEvents: true, eventValidation: true, eventReader: bytes.NewReader(input)}
+			assert.NoError(t, plugin.readSensuEvent())
+			if name == "single" {
+				assert.Equal(t, "check-1", plugin.sensuEvent.Check.Name)
+				assert.Nil(t, plugin.sensuEvents)
+				return
+			}
+			assert.Nil(t, plugin.sensuEvent)
+			assert.Len(t, plugin.sensuEvents, 2)
+			assert.Equal(t, "check-2", plugin.sensuEvents[1].Check.Name)
+		})
+	}
+}
+
+func TestReadSensuEvents_Invalid(t *testing.T) {
+	valid := marshalNDJSON(t, []*types.Event{readTestEvent(t, "test/event-no-override.json", "check-1")})
+	tests := map[string]string{
+		"empty array":  "[]",
+		"null event":   "[null]",
+		"invalid json": string(valid) + "{",
+		"no timestamp": string(valid) + `{"entity":{}}`,
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			plugin := &basePlugin{batchEvents: true, eventValidation: true, eventReader: bytes.NewReader([]byte(input))}
+			assert.Error(t, plugin.readSensuEvent())
+		})
+	}
+}
+
+func TestSnapshotOptions(t *testing.T) {
+	str := "a"
+	slice := []string{"a"}
+	strMap := map[string]string{"a": "a"}
+	plugin := &basePlugin{options: []*PluginConfigOption{{Value: &str}, {Value: &slice}, {Value: &strMap}, {}}}
+	restore := plugin.snapshotOptions()
+	str = "b"
+	slice[0] = "b"
+	strMap["a"] = "b"
+	restore()
+	assert.Equal(t, "a", str)
+	assert.Equal(t, []string{"a"}, slice)
+	assert.Equal(t, map[string]string{"a": "a"}, strMap)
+}
+
+func TestAnnotationOverride(t *testing.T) {
+	keyspace := "sensu.io/plugins/segp/config"
+	event := &types.Event{
+		Entity: &types.Entity{ObjectMeta: types.ObjectMeta{Annotations: map[string]string{
+			keyspace + "/template": "entity template",
+			keyspace + "/channel":  "#entity",
+		}}},
+		Check: &types.Check{ObjectMeta: types.ObjectMeta{Annotations: map[string]string{
+			keyspace + "/channel": "#check",
+		}}},
+	}
+
+	value, ok := AnnotationOverride(event, keyspace, "template")
+	assert.True(t, ok)
+	assert.Equal(t, "entity template", value)
+
+	value, ok = AnnotationOverride(event, keyspace, "channel")
+	assert.True(t, ok)
+	assert.Equal(t, "#check", value)
+
+	_, ok = AnnotationOverride(event, keyspace, "missing")
+	assert.False(t, ok)
+	_, ok = AnnotationOverride(event, "", "channel")
+	assert.False(t, ok)
+	_, ok = AnnotationOverride(nil, keyspace, "channel")
+	assert.False(t, ok)
+}
+
+// readTestEvent reads an event from a test file and sets its check name
+func readTestEvent(t *testing.T, file string, checkName string) *types.Event {
+	t.Helper()
+	eventJSON, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := &types.Event{}
+	if err := json.Unmarshal(eventJSON, event); err != nil {
+		t.Fatal(err)
+	}
+	event.Check.Name = checkName
+	return event
+}
+
+func marshalNDJSON(t *testing.T, events []*types.Event) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
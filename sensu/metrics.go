@@ -0,0 +1,245 @@
+package sensu
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+// Metric formats understood by the Sensu agent, see the output_metric_format
+// attribute of a check.
+const (
+	MetricFormatGraphite   = "graphite_plaintext"
+	MetricFormatInfluxDB   = "influxdb_line"
+	MetricFormatOpenTSDB   = "opentsdb_line"
+	MetricFormatNagios     = "nagios_perfdata"
+	MetricFormatPrometheus = "prometheus_text"
+)
+
+// MetricFormats lists the supported metric formats.
+var MetricFormats = []string{
+	MetricFormatGraphite,
+	MetricFormatInfluxDB,
+	MetricFormatOpenTSDB,
+	MetricFormatNagios,
+	MetricFormatPrometheus,
+}
+
+// Metrics accumulates the metric points emitted by a check.
+type Metrics struct {
+	Points []*types.MetricPoint
+}
+
+// Add appends a new metric point, timestamped with the current time, and
+// returns it so its timestamp can be adjusted if needed.
+func (m *Metrics) Add(name string, value float64, tags map[string]string) *types.MetricPoint {
+	point := NewMetricPoint(name, value, tags)
+	m.Points = append(m.Points, point)
+	return point
+}
+
+// NewMetricPoint creates a metric point timestamped with the current time, in
+// seconds since the Unix epoch. Tags are sorted by name.
+func NewMetricPoint(name string, value float64, tags map[string]string) *types.MetricPoint {
+	names := make([]string, 0, len(tags))
+	for tagName := range tags {
+		names = append(names, tagName)
+	}
+	sort.Strings(names)
+	metricTags := make([]*types.MetricTag, 0, len(tags))
+	for _, tagName := range names {
+		metricTags = append(metricTags, &types.MetricTag{Name: tagName, Value: tags[tagName]})
+	}
+	return &types.MetricPoint{
+		Name:      name,
+		Value:     value,
+		Timestamp: time.Now().Unix(),
+		Tags:      metricTags,
+	}
+}
+
+// FormatMetrics serializes metric points in the given format, one point per
+// line. For MetricFormatNagios only the performance data is returned, which
+// must follow a "|" in the check output.
+//
+// Tags are not supported by the Graphite and Nagios formats and are omitted.
+// Points with a NaN or infinite value are omitted in the InfluxDB format,
+// which can't represent them.
+func FormatMetrics(format string, points []*types.MetricPoint) (string, error) {
+	var formatPoint func(*types.MetricPoint) string
+	switch format {
+	case MetricFormatGraphite:
+		formatPoint = formatGraphite
+	case MetricFormatInfluxDB:
+		formatPoint = formatInfluxDB
+	case MetricFormatOpenTSDB:
+		formatPoint = formatOpenTSDB
+	case MetricFormatNagios:
+		perfdata := make([]string, 0, len(points))
+		for _, point := range points {
+			perfdata = append(perfdata, formatNagios(point))
+		}
+		return strings.Join(perfdata, " "), nil
+	case MetricFormatPrometheus:
+		formatPoint = formatPrometheus
+	default:
+		return "", fmt.Errorf("invalid metric format %q, must be one of %s", format, strings.Join(MetricFormats, ", "))
+	}
+	var sb strings.Builder
+	for _, point := range points {
+		line := formatPoint(point)
+		if line == "" {
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// path value timestamp
+func formatGraphite(point *types.MetricPoint) string {
+	name := strings.Replace(point.Name, " ", "_", -1)
+	return fmt.Sprintf("%s %s %d", name, formatValue(point.Value), point.Timestamp)
+}
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+)
+
+// measurement[,tag=value...] field=value timestamp
+//
+// The point name is split on its last dot into the measurement and the field
+// key, so the agent reassembles the original name. Timestamps are written in
+// nanoseconds. Points with a NaN or infinite value are skipped, returning an
+// empty line.
+func formatInfluxDB(point *types.MetricPoint) string {
+	if math.IsNaN(point.Value) || math.IsInf(point.Value, 0) {
+		return ""
+	}
+	measurement, field := point.Name, "value"
+	if i := strings.LastIndex(point.Name, "."); i > 0 && i < len(point.Name)-1 {
+		measurement, field = point.Name[:i], point.Name[i+1:]
+	}
+	var sb strings.Builder
+	sb.WriteString(influxMeasurementEscaper.Replace(measurement))
+	for _, tag := range point.Tags {
+		sb.WriteByte(',')
+		sb.WriteString(influxTagEscaper.Replace(tag.Name))
+		sb.WriteByte('=')
+		sb.WriteString(influxTagEscaper.Replace(tag.Value))
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(influxTagEscaper.Replace(field))
+	sb.WriteByte('=')
+	sb.WriteString(formatValue(point.Value))
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatInt(time.Unix(point.Timestamp, 0).UnixNano(), 10))
+	return sb.String()
+}
+
+// openTSDBTagReplacer replaces the separators of tags with underscores.
+var openTSDBTagReplacer = strings.NewReplacer(" ", "_", "=", "_")
+
+// metric timestamp value [tag=value...]
+func formatOpenTSDB(point *types.MetricPoint) string {
+	var sb strings.Builder
+	sb.WriteString(strings.Replace(point.Name, " ", "_", -1))
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatInt(point.Timestamp, 10))
+	sb.WriteByte(' ')
+	sb.WriteString(formatValue(point.Value))
+	for _, tag := range point.Tags {
+		sb.WriteByte(' ')
+		sb.WriteString(openTSDBTagReplacer.Replace(tag.Name))
+		sb.WriteByte('=')
+		sb.WriteString(openTSDBTagReplacer.Replace(tag.Value))
+	}
+	return sb.String()
+}
+
+// 'label'=value
+func formatNagios(point *types.MetricPoint) string {
+	label := point.Name
+	if strings.ContainsAny(label, " '=") {
+		label = "'" + strings.Replace(label, "'", "''", -1) + "'"
+	}
+	return label + "=" + formatValue(point.Value)
+}
+
+var (
+	prometheusInvalidName  = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	prometheusInvalidLabel = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+	prometheusEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+// name{label="value"...} value timestamp
+//
+// Invalid characters in names are replaced with underscores. Timestamps are
+// written in milliseconds.
+func formatPrometheus(point *types.MetricPoint) string {
+	var sb strings.Builder
+	sb.WriteString(prometheusName(prometheusInvalidName, point.Name))
+	if len(point.Tags) > 0 {
+		sb.WriteByte('{')
+		for i, tag := range point.Tags {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(prometheusName(prometheusInvalidLabel, tag.Name))
+			sb.WriteString(`="`)
+			sb.WriteString(prometheusEscaper.Replace(tag.Value))
+			sb.WriteByte('"')
+		}
+		sb.WriteByte('}')
+	}
+	sb.WriteByte(' ')
+	switch {
+	case math.IsNaN(point.Value):
+		sb.WriteString("NaN")
+	case math.IsInf(point.Value, 1):
+		sb.WriteString("+Inf")
+	case math.IsInf(point.Value, -1):
+		sb.WriteString("-Inf")
+	default:
+		sb.WriteString(formatValue(point.Value))
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatInt(point.Timestamp*1000, 10))
+	return sb.String()
+}
+
+func prometheusName(invalid *regexp.Regexp, name string) string {
+	name = invalid.ReplaceAllString(name, "_")
+	if len(name) > 0 && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// metricFormatArgument is the command line argument of metricFormatOption.
+const metricFormatArgument = "metric-format"
+
+// metricFormatOption is the standard option used by checks emitting metrics
+// to select the output format.
+func metricFormatOption(format *string) *PluginConfigOption {
+	return &PluginConfigOption{
+		Value:    format,
+		Path:     "metric-format",
+		Env:      "METRIC_FORMAT",
+		Argument: metricFormatArgument,
+		Default:  MetricFormatNagios,
+		Usage:    "Metric output format, one of " + strings.Join(MetricFormats, ", "),
+	}
+}
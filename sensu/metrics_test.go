@@ -0,0 +1,92 @@
+package sensu
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMetricPoints() []*types.MetricPoint {
+	point1 := NewMetricPoint("disk.used", 42.5, map[string]string{"mount": "/var", "host": "web 01"})
+	point1.Timestamp = 1600000000
+	point2 := NewMetricPoint("load", 1, nil)
+	point2.Timestamp = 1600000001
+	return []*types.MetricPoint{point1, point2}
+}
+
+func TestNewMetricPoint(t *testing.T) {
+	before := time.Now().Unix()
+	point := NewMetricPoint("cpu", 3.5, map[string]string{"b": "2", "a": "1"})
+	assert.Equal(t, "cpu", point.Name)
+	assert.Equal(t, 3.5, point.Value)
+	assert.True(t, point.Timestamp >= before)
+	assert.Equal(t, []*types.MetricTag{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, point.Tags)
+}
+
+func TestMetrics_Add(t *testing.T) {
+	metrics := Metrics{}
+	metrics.Add("cpu", 1, nil)
+	metrics.Add("mem", 2, nil).Timestamp = 10
+	assert.Len(t, metrics.Points, 2)
+	assert.Equal(t, "mem", metrics.Points[1].Name)
+	assert.Equal(t, int64(10), metrics.Points[1].Timestamp)
+}
+
+func TestFormatMetrics_Graphite(t *testing.T) {
+	output, err := FormatMetrics(MetricFormatGraphite, testMetricPoints())
+	assert.NoError(t, err)
+	assert.Equal(t, "disk.used 42.5 1600000000\nload 1 1600000001\n", output)
+}
+
+func TestFormatMetrics_InfluxDB(t *testing.T) {
+	output, err := FormatMetrics(MetricFormatInfluxDB, testMetricPoints())
+	assert.NoError(t, err)
+	assert.Equal(t, "disk,host=web\\ 01,mount=/var used=42.5 1600000000000000000\n"+
+		"load value=1 1600000001000000000\n", output)
+
+	// line protocol has no representation of NaN and infinite values
+	points := testMetricPoints()
+	points = append(points, NewMetricPoint("nan", math.NaN(), nil), NewMetricPoint("inf", math.Inf(-1), nil))
+	points[1].Value = math.Inf(1)
+	output, err = FormatMetrics(MetricFormatInfluxDB, points)
+	assert.NoError(t, err)
+	assert.Equal(t, "disk,host=web\\ 01,mount=/var used=42.5 1600000000000000000\n", output)
+}
+
+func TestFormatMetrics_OpenTSDB(t *testing.T) {
+	output, err := FormatMetrics(MetricFormatOpenTSDB, testMetricPoints())
+	assert.NoError(t, err)
+	assert.Equal(t, "disk.used 1600000000 42.5 host=web_01 mount=/var\nload 1600000001 1\n", output)
+
+	points := testMetricPoints()
+	points[1].Tags = []*types.MetricTag{{Name: "a=b", Value: "x=1 y"}}
+	output, err = FormatMetrics(MetricFormatOpenTSDB, points)
+	assert.NoError(t, err)
+	assert.Equal(t, "disk.used 1600000000 42.5 host=web_01 mount=/var\nload 1600000001 1 a_b=x_1_y\n", output)
+}
+
+func TestFormatMetrics_Nagios(t *testing.T) {
+	points := testMetricPoints()
+	points = append(points, NewMetricPoint("free space", 3, nil))
+	output, err := FormatMetrics(MetricFormatNagios, points)
+	assert.NoError(t, err)
+	assert.Equal(t, "disk.used=42.5 load=1 'free space'=3", output)
+}
+
+func TestFormatMetrics_Prometheus(t *testing.T) {
+	points := testMetricPoints()
+	points[1].Value = math.Inf(1)
+	points[1].Tags = []*types.MetricTag{{Name: "quote", Value: `a"b`}}
+	output, err := FormatMetrics(MetricFormatPrometheus, points)
+	assert.NoError(t, err)
+	assert.Equal(t, "disk_used{host=\"web 01\",mount=\"/var\"} 42.5 1600000000000\n"+
+		"load{quote=\"a\\\"b\"} +Inf 1600000001000\n", output)
+}
+
+func TestFormatMetrics_InvalidFormat(t *testing.T) {
+	_, err := FormatMetrics("json", testMetricPoints())
+	assert.Error(t, err)
+}
@@ -1,7 +1,11 @@
 package sensu
 
 import (
+	"bytes"
+	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
+	"fmt"
 	"io/ioutil"
 )
 
@@ -9,17 +13,25 @@ import (
 // backend.
 type SecurityConfig struct {
 	// CACertificate provide a means to use a self-signed certificate with
-	// an HTTP client.
+	// an HTTP client. The file can hold a single DER encoded certificate, or
+	// one or more PEM encoded certificates.
 	CACertificate string
 
 	// InsecureSkipVerify skips hostname verification for certificates. It is
 	// not recommended to use this outside of testing.
 	InsecureSkipVerify bool
+
+	// ClientCertificate and ClientKey are the paths of the certificate and
+	// private key used to authenticate with backends requiring mutual TLS.
+	ClientCertificate string
+	ClientKey         string
 }
 
 // SensuSecurityOptions adds the following flags to a plugin:
 //   --sensu-ca-cert
 //   --sensu-insecure-skip-verify
+//   --sensu-client-cert
+//   --sensu-client-key
 func SensuSecurityOptions(config *SecurityConfig) []*PluginConfigOption {
 	return []*PluginConfigOption{
 		{
@@ -36,17 +48,114 @@ func SensuSecurityOptions(config *SecurityConfig) []*PluginConfigOption {
 			Argument: "sensu-insecure-skip-verify",
 			Usage:    "--sensu-insecure-skip-verify (disables TLS hostname verification)",
 		},
+		{
+			Value:    &config.ClientCertificate,
+			Path:     "sensu-client-cert",
+			Env:      "SENSU_CLIENT_CERT",
+			Argument: "sensu-client-cert",
+			Usage:    "--sensu-client-cert /etc/ssl/client.crt (certificate for mutual TLS)",
+		},
+		{
+			Value:    &config.ClientKey,
+			Path:     "sensu-client-key",
+			Env:      "SENSU_CLIENT_KEY",
+			Argument: "sensu-client-key",
+			Usage:    "--sensu-client-key /etc/ssl/client.key (private key for mutual TLS)",
+		},
 	}
 }
 
 // GetCACertificate gets the CA certificate associated with the path stored at
 // CACertificate. It returns an error if the file is not found, or if the
 // certificate is not a valid x509 certificate. The certificate can be provided
-// to the CoreClient in the httpclient package.
+// to the CoreClient in the httpclient package. When the file holds a bundle of
+// certificates, the first one is returned, use GetCACertPool to get them all.
 func (s *SecurityConfig) GetCACertificate() (*x509.Certificate, error) {
+	certs, err := s.GetCACertificates()
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// GetCACertificates gets all the certificates of the file stored at
+// CACertificate, either PEM or DER encoded. It returns an error if the file
+// is not found, or if it does not hold any valid x509 certificate.
+func (s *SecurityConfig) GetCACertificates() ([]*x509.Certificate, error) {
 	b, err := ioutil.ReadFile(s.CACertificate)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CA certificate: %s", err)
+	}
+	certs, err := parseCertificates(b)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing CA certificate %s: %s", s.CACertificate, err)
+	}
+	return certs, nil
+}
+
+// GetCACertPool gets a certificate pool with the system certificates and all
+// the certificates of the file stored at CACertificate. The pool can be
+// provided to the CoreClient in the httpclient package.
+func (s *SecurityConfig) GetCACertPool() (*x509.CertPool, error) {
+	certs, err := s.GetCACertificates()
 	if err != nil {
 		return nil, err
 	}
-	return x509.ParseCertificate(b)
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+// parseCertificates parses PEM encoded certificates, or DER encoded ones if no
+// PEM block is found. PEM blocks other than certificates are ignored.
+func parseCertificates(b []byte) ([]*x509.Certificate, error) {
+	var (
+		certs  []*x509.Certificate
+		blocks int
+		rest   = b
+	)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		blocks++
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate in PEM block %d: %s", blocks, err)
+		}
+		certs = append(certs, cert)
+	}
+	if blocks > 0 {
+		if len(certs) == 0 {
+			return nil, fmt.Errorf("no CERTIFICATE PEM block found")
+		}
+		return certs, nil
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, fmt.Errorf("empty file")
+	}
+	certs, err := x509.ParseCertificates(b)
+	if err != nil {
+		return nil, fmt.Errorf("neither PEM nor valid DER encoded certificates: %s", err)
+	}
+	return certs, nil
+}
+
+// GetClientCertificate loads the key pair stored at ClientCertificate and
+// ClientKey. It returns an error if either file is not found, or if they do not
+// hold a matching PEM encoded certificate and private key. To reload the key
+// pair when the files are rotated, pass the paths to the CoreClient in the
+// httpclient package instead.
+func (s *SecurityConfig) GetClientCertificate() (tls.Certificate, error) {
+	return tls.LoadX509KeyPair(s.ClientCertificate, s.ClientKey)
 }
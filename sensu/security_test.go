@@ -1,13 +1,22 @@
 package sensu_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sensu/sensu-plugin-sdk/sensu"
 )
@@ -51,3 +60,185 @@ func TestSecurityConfig(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// writeKeyPair writes a self-signed certificate and its private key, PEM
+// encoded, to files in dir.
+func writeKeyPair(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestSecurityConfigClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeKeyPair(t, dir, "client")
+	_, otherKeyFile := writeKeyPair(t, dir, "other")
+
+	cfg := sensu.SecurityConfig{
+		ClientCertificate: certFile,
+		ClientKey:         keyFile,
+	}
+	cert, err := cfg.GetClientCertificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf.Subject.CommonName != "client" {
+		t.Fatalf("bad certificate: %s", leaf.Subject.CommonName)
+	}
+
+	cfg.ClientKey = otherKeyFile
+	if _, err := cfg.GetClientCertificate(); err == nil {
+		t.Fatal("expected an error for a mismatched key")
+	}
+}
+
+func writeTempFile(t *testing.T, dir string, data []byte) string {
+	t.Helper()
+	tf, err := ioutil.TempFile(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tf.Close()
+	if _, err := tf.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	return tf.Name()
+}
+
+func TestSecurityConfigCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	otherCertFile, _ := writeKeyPair(t, dir, "intermediate")
+	bundle, err := ioutil.ReadFile(otherCertFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})...)
+	cfg := sensu.SecurityConfig{
+		CACertificate: writeTempFile(t, dir, bundle),
+	}
+
+	certs, err := cfg.GetCACertificates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(certs))
+	}
+	cert, err := cfg.GetCACertificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Subject.CommonName != "intermediate" {
+		t.Fatalf("bad first certificate: %s", cert.Subject.CommonName)
+	}
+
+	pool, err := cfg.GetCACertPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs: pool,
+			},
+		},
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestSecurityConfigCACertificateErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	_, keyFile := writeKeyPair(t, dir, "key")
+
+	tests := []struct {
+		name string
+		file string
+		err  string
+	}{
+		{
+			name: "missing file",
+			file: filepath.Join(dir, "missing.crt"),
+			err:  "error reading CA certificate",
+		},
+		{
+			name: "empty file",
+			file: writeTempFile(t, dir, nil),
+			err:  "empty file",
+		},
+		{
+			name: "garbage",
+			file: writeTempFile(t, dir, []byte("not a certificate")),
+			err:  "neither PEM nor valid DER encoded certificates",
+		},
+		{
+			name: "private key",
+			file: keyFile,
+			err:  "no CERTIFICATE PEM block found",
+		},
+		{
+			name: "invalid PEM certificate",
+			file: writeTempFile(t, dir, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")})),
+			err:  "invalid certificate in PEM block 1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := sensu.SecurityConfig{CACertificate: test.file}
+			_, err := cfg.GetCACertPool()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("bad error: got %q, want %q", err, test.err)
+			}
+		})
+	}
+}
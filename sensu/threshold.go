@@ -0,0 +1,146 @@
+package sensu
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Range is a threshold range using the Nagios plugin range syntax:
+//
+//	10      alert if value < 0 or > 10
+//	10:     alert if value < 10
+//	~:10    alert if value > 10
+//	10:20   alert if value < 10 or > 20
+//	@10:20  alert if 10 <= value <= 20
+//
+// A *Range can be used as the Value of a PluginConfigOption, in which case
+// the option Default, if any, must be a string in range syntax.
+type Range struct {
+	Start  float64
+	End    float64
+	Inside bool
+
+	raw string
+}
+
+// ParseRange parses a range using the Nagios plugin range syntax.
+func ParseRange(s string) (*Range, error) {
+	r := new(Range)
+	if err := r.Set(s); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Set parses s into the range. It implements pflag.Value.
+func (r *Range) Set(s string) error {
+	spec := strings.TrimSpace(s)
+	parsed := Range{Start: 0, End: math.Inf(1), raw: spec}
+	if strings.HasPrefix(spec, "@") {
+		parsed.Inside = true
+		spec = spec[1:]
+	}
+	if len(spec) == 0 {
+		return fmt.Errorf("invalid range %q: range is empty", s)
+	}
+
+	start, end := "", spec
+	if i := strings.Index(spec, ":"); i >= 0 {
+		start, end = spec[:i], spec[i+1:]
+	}
+
+	var err error
+	switch start {
+	case "":
+	case "~":
+		parsed.Start = math.Inf(-1)
+	default:
+		if parsed.Start, err = strconv.ParseFloat(start, 64); err != nil {
+			return fmt.Errorf("invalid range %q: invalid start %q", s, start)
+		}
+	}
+	if len(end) > 0 {
+		if parsed.End, err = strconv.ParseFloat(end, 64); err != nil {
+			return fmt.Errorf("invalid range %q: invalid end %q", s, end)
+		}
+	}
+	if parsed.Start > parsed.End {
+		return fmt.Errorf("invalid range %q: start is greater than end", s)
+	}
+
+	*r = parsed
+	return nil
+}
+
+// String returns the range as it was set. It implements pflag.Value.
+func (r *Range) String() string {
+	return r.raw
+}
+
+// Type implements pflag.Value.
+func (r *Range) Type() string {
+	return "range"
+}
+
+// IsSet returns true if the range has been set.
+func (r *Range) IsSet() bool {
+	return r != nil && len(r.raw) > 0
+}
+
+// Alert returns true if the value should raise an alert, that is if it lies
+// outside of the range, or inside of it for ranges starting with "@".
+func (r *Range) Alert(value float64) bool {
+	inside := value >= r.Start && value <= r.End
+	if r.Inside {
+		return inside
+	}
+	return !inside
+}
+
+// Thresholds holds the warning and critical ranges of a check, typically
+// configured with the --warning and --critical options.
+type Thresholds struct {
+	Warning  Range
+	Critical Range
+}
+
+// Status returns CheckStateCritical if the value raises an alert for the
+// critical range, CheckStateWarning if it does for the warning range and
+// CheckStateOK otherwise. Ranges that were not set never raise an alert.
+func (t *Thresholds) Status(value float64) int {
+	switch {
+	case t.Critical.IsSet() && t.Critical.Alert(value):
+		return CheckStateCritical
+	case t.Warning.IsSet() && t.Warning.Alert(value):
+		return CheckStateWarning
+	default:
+		return CheckStateOK
+	}
+}
+
+// ThresholdOptions adds the following flags to a plugin:
+//
+//	--warning
+//	--critical
+func ThresholdOptions(thresholds *Thresholds) []*PluginConfigOption {
+	return []*PluginConfigOption{
+		{
+			Value:     &thresholds.Warning,
+			Path:      "warning",
+			Env:       "CHECK_WARNING",
+			Argument:  "warning",
+			Shorthand: "w",
+			Usage:     "Warning threshold, using the Nagios range syntax (e.g. 10, 10:, ~:10, 10:20, @10:20)",
+		},
+		{
+			Value:     &thresholds.Critical,
+			Path:      "critical",
+			Env:       "CHECK_CRITICAL",
+			Argument:  "critical",
+			Shorthand: "c",
+			Usage:     "Critical threshold, using the Nagios range syntax (e.g. 10, 10:, ~:10, 10:20, @10:20)",
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package sensu
+
+import (
+	"math"
+	"testing"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		spec   string
+		start  float64
+		end    float64
+		inside bool
+	}{
+		{spec: "10", start: 0, end: 10},
+		{spec: "10:", start: 10, end: math.Inf(1)},
+		{spec: "~:10", start: math.Inf(-1), end: 10},
+		{spec: "10:20", start: 10, end: 20},
+		{spec: "@10:20", start: 10, end: 20, inside: true},
+		{spec: "-5.5:5", start: -5.5, end: 5},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			r, err := ParseRange(test.spec)
+			assert.NoError(t, err)
+			assert.Equal(t, test.start, r.Start)
+			assert.Equal(t, test.end, r.End)
+			assert.Equal(t, test.inside, r.Inside)
+			assert.Equal(t, test.spec, r.String())
+		})
+	}
+}
+
+func TestParseRange_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "@", "abc", "10:abc", "20:10", "1:2:3"} {
+		_, err := ParseRange(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestRange_Alert(t *testing.T) {
+	tests := []struct {
+		spec  string
+		value float64
+		alert bool
+	}{
+		{spec: "10", value: -1, alert: true},
+		{spec: "10", value: 0, alert: false},
+		{spec: "10", value: 10, alert: false},
+		{spec: "10", value: 11, alert: true},
+		{spec: "10:", value: 9, alert: true},
+		{spec: "10:", value: 1e9, alert: false},
+		{spec: "~:10", value: -1e9, alert: false},
+		{spec: "~:10", value: 11, alert: true},
+		{spec: "10:20", value: 15, alert: false},
+		{spec: "10:20", value: 21, alert: true},
+		{spec: "@10:20", value: 10, alert: true},
+		{spec: "@10:20", value: 21, alert: false},
+	}
+	for _, test := range tests {
+		r, err := ParseRange(test.spec)
+		assert.NoError(t, err)
+		assert.Equal(t, test.alert, r.Alert(test.value), "%s %v", test.spec, test.value)
+	}
+}
+
+func TestThresholds_Status(t *testing.T) {
+	thresholds := Thresholds{}
+	assert.Equal(t, CheckStateOK, thresholds.Status(100))
+
+	assert.NoError(t, thresholds.Warning.Set("80"))
+	assert.NoError(t, thresholds.Critical.Set("90"))
+	assert.Equal(t, CheckStateOK, thresholds.Status(50))
+	assert.Equal(t, CheckStateWarning, thresholds.Status(85))
+	assert.Equal(t, CheckStateCritical, thresholds.Status(95))
+}
+
+func TestThresholdOptions(t *testing.T) {
+	thresholds := Thresholds{}
+	options := ThresholdOptions(&thresholds)
+	options[0].Default = "50"
+	var status int
+	goCheck := NewGoCheck(&defaultCheckConfig, options, func(_ *types.Event) (int, error) {
+		return 0, nil
+	}, func(_ *types.Event) (int, error) {
+		return thresholds.Status(75), nil
+	}, false)
+	goCheck.cmd.SetArgs([]string{"--critical", "@70:80"})
+	goCheck.exitFunction = func(i int) {
+		status = i
+	}
+	goCheck.Execute()
+
+	assert.Equal(t, "50", thresholds.Warning.String())
+	assert.Equal(t, "@70:80", thresholds.Critical.String())
+	assert.Equal(t, CheckStateCritical, status)
+}
+
+func TestThresholdOptions_ExecuteWithEnvironment(t *testing.T) {
+	thresholds := Thresholds{}
+	options := ThresholdOptions(&thresholds)
+	goCheck := NewGoCheck(&defaultCheckConfig, options, func(_ *types.Event) (int, error) {
+		return 0, nil
+	}, func(_ *types.Event) (int, error) {
+		return thresholds.Status(75), nil
+	}, false)
+
+	status := goCheck.ExecuteWithEnvironment(Environment{Args: []string{"--warning", "10"}, Env: map[string]string{}})
+	assert.Equal(t, CheckStateWarning, status)
+	assert.Equal(t, "10", thresholds.Warning.String())
+
+	status = goCheck.ExecuteWithEnvironment(Environment{Env: map[string]string{}})
+	assert.Equal(t, CheckStateOK, status)
+	assert.False(t, thresholds.Warning.IsSet())
+
+	status = goCheck.ExecuteWithEnvironment(Environment{Env: map[string]string{"CHECK_CRITICAL": "50"}})
+	assert.Equal(t, CheckStateCritical, status)
+	assert.False(t, thresholds.Warning.IsSet())
+	assert.Equal(t, "50", thresholds.Critical.String())
+}
+
+func TestSetOptionValue_Range(t *testing.T) {
+	r := Range{}
+	option := defaultOption1
+	option.Value = &r
+	err := setOptionValue(&option, "10:20")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(10), r.Start)
+	assert.Equal(t, float64(20), r.End)
+}
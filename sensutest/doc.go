@@ -0,0 +1,4 @@
+// Package sensutest runs Sensu plugins built with the sensu package in
+// process, with a supplied event, arguments and environment, and captures
+// their output and exit status. It is intended for plugin unit tests.
+package sensutest
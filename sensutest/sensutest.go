@@ -0,0 +1,66 @@
+package sensutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/sensu/sensu-plugin-sdk/sensu"
+)
+
+// Plugin is implemented by all the plugin types of the sensu package, such
+// as *sensu.GoCheck, *sensu.GoHandler and *sensu.GoMutator.
+type Plugin interface {
+	ExecuteWithEnvironment(env sensu.Environment) int
+}
+
+// Result holds the outcome of a plugin execution.
+type Result struct {
+	// Status is the exit status of the plugin.
+	Status int
+
+	// Stdout and Stderr hold the output of the plugin.
+	Stdout string
+	Stderr string
+}
+
+// Run executes the plugin with the event marshaled to JSON on stdin, the
+// command line arguments args and the environment variables env. A nil event
+// leaves stdin empty. The environment of the test process is never consulted.
+func Run(plugin Plugin, event *types.Event, args []string, env map[string]string) (*Result, error) {
+	var stdin io.Reader
+	if event != nil {
+		eventJSON, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		stdin = bytes.NewReader(eventJSON)
+	}
+	return RunWithStdin(plugin, stdin, args, env), nil
+}
+
+// RunWithStdin executes the plugin like Run, reading stdin from the supplied
+// reader. It is useful to test how a plugin deals with invalid input.
+func RunWithStdin(plugin Plugin, stdin io.Reader, args []string, env map[string]string) *Result {
+	if env == nil {
+		env = map[string]string{}
+	}
+	if args == nil {
+		args = []string{}
+	}
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	status := plugin.ExecuteWithEnvironment(sensu.Environment{
+		Args:   args,
+		Env:    env,
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	return &Result{
+		Status: status,
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+	}
+}
@@ -0,0 +1,178 @@
+package sensutest_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-go/types"
+	"github.com/sensu/sensu-plugin-sdk/sensu"
+	"github.com/sensu/sensu-plugin-sdk/sensutest"
+	"github.com/stretchr/testify/assert"
+)
+
+var pluginConfig = sensu.PluginConfig{
+	Name:     "sensutest",
+	Short:    "Test plugin",
+	Keyspace: "sensu.io/plugins/sensutest/config",
+}
+
+func nameOption(value *string) *sensu.PluginConfigOption {
+	return &sensu.PluginConfigOption{
+		Value:    value,
+		Path:     "name",
+		Env:      "SENSUTEST_NAME",
+		Argument: "name",
+		Default:  "default",
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	var name string
+	check := sensu.NewGoCheckWithResult(&pluginConfig, []*sensu.PluginConfigOption{nameOption(&name)},
+		func(*types.Event) (int, error) {
+			return sensu.CheckStateOK, nil
+		}, func(context.Context, *types.Event) (*sensu.CheckResult, error) {
+			return &sensu.CheckResult{Status: sensu.CheckStateWarning, Output: "hello " + name}, nil
+		}, false)
+
+	result, err := sensutest.Run(check, nil, []string{"--name", "arg"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, sensu.CheckStateWarning, result.Status)
+	assert.Equal(t, "sensutest WARNING: hello arg\n", result.Stdout)
+
+	// Options are set up again on each run
+	result, err = sensutest.Run(check, nil, nil, map[string]string{"SENSUTEST_NAME": "env"})
+	assert.NoError(t, err)
+	assert.Equal(t, "sensutest WARNING: hello env\n", result.Stdout)
+
+	result, err = sensutest.Run(check, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "sensutest WARNING: hello default\n", result.Stdout)
+}
+
+func TestRunCheckTimeout(t *testing.T) {
+	var name string
+	config := pluginConfig
+	config.Timeout = 1
+	check := sensu.NewGoCheckWithResult(&config, []*sensu.PluginConfigOption{nameOption(&name)},
+		func(*types.Event) (int, error) {
+			return sensu.CheckStateOK, nil
+		}, func(ctx context.Context, _ *types.Event) (*sensu.CheckResult, error) {
+			if name == "slow" {
+				<-ctx.Done()
+				// keep running after the timeout
+				time.Sleep(100 * time.Millisecond)
+			}
+			return &sensu.CheckResult{Status: sensu.CheckStateOK, Output: "hello " + name}, nil
+		}, false)
+
+	result, err := sensutest.Run(check, nil, []string{"--name", "slow"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, sensu.CheckStateUnknown, result.Status)
+	assert.Equal(t, "sensutest UNKNOWN: timed out after 1s\n", result.Stdout)
+
+	// the next run doesn't overlap the workflow that timed out
+	result, err = sensutest.Run(check, nil, []string{"--name", "fast"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, sensu.CheckStateOK, result.Status)
+	assert.Equal(t, "sensutest OK: hello fast\n", result.Stdout)
+}
+
+func TestRunHandler(t *testing.T) {
+	var name string
+	var handled *types.Event
+	handler := sensu.NewGoHandler(&pluginConfig, []*sensu.PluginConfigOption{nameOption(&name)},
+		func(*types.Event) error {
+			return nil
+		}, func(event *types.Event) error {
+			handled = event
+			if name != "ok" {
+				return errors.New("not ok")
+			}
+			return nil
+		})
+
+	event := corev2.FixtureEvent("entity", "check")
+	result, err := sensutest.Run(handler, event, []string{"--name", "ok"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Status)
+	assert.Equal(t, "entity", handled.Entity.Name)
+
+	result, err = sensutest.Run(handler, event, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Status)
+	assert.Contains(t, result.Stderr, "error executing handler: not ok")
+
+	result = sensutest.RunWithStdin(handler, strings.NewReader("{"), nil, nil)
+	assert.Equal(t, 1, result.Status)
+	assert.Contains(t, result.Stderr, "Failed to unmarshal STDIN data")
+}
+
+func TestRunEnterpriseHandler(t *testing.T) {
+	handled := false
+	handler := sensu.NewEnterpriseGoHandler(&pluginConfig, nil,
+		func(*types.Event) error {
+			return nil
+		}, func(*types.Event) error {
+			handled = true
+			return nil
+		})
+	event := corev2.FixtureEvent("entity", "check")
+
+	// the license of the test process must not be used
+	os.Setenv("SENSU_LICENSE_FILE", "{")
+	defer os.Unsetenv("SENSU_LICENSE_FILE")
+	result, err := sensutest.Run(handler, event, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Status)
+	assert.Contains(t, result.Stderr, "valid sensu license is required")
+
+	result, err = sensutest.Run(handler, event, nil, map[string]string{"SENSU_LICENSE_FILE": "{}"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Status)
+	assert.Contains(t, result.Stderr, "error validating license file")
+	assert.False(t, handled)
+}
+
+func TestRunMutator(t *testing.T) {
+	mutator := sensu.NewGoMutator(&pluginConfig, nil,
+		func(*types.Event) error {
+			return nil
+		}, func(event *types.Event) (*types.Event, error) {
+			event.Check.Output = "mutated"
+			return event, nil
+		})
+
+	result, err := sensutest.Run(mutator, corev2.FixtureEvent("entity", "check"), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Status)
+	assert.Contains(t, result.Stdout, `"output":"mutated"`)
+}
+
+func TestRunMultiplePlugins(t *testing.T) {
+	var name1, name2 string
+	option2 := nameOption(&name2)
+	option2.Default = "other default"
+	check1 := sensu.NewGoCheck(&pluginConfig, []*sensu.PluginConfigOption{nameOption(&name1)},
+		func(*types.Event) (int, error) {
+			return 0, nil
+		}, func(*types.Event) (int, error) {
+			return 0, nil
+		}, false)
+	check2 := sensu.NewGoCheck(&pluginConfig, []*sensu.PluginConfigOption{option2},
+		func(*types.Event) (int, error) {
+			return 0, nil
+		}, func(*types.Event) (int, error) {
+			return 0, nil
+		}, false)
+
+	_, _ = sensutest.Run(check1, nil, []string{"--name", "first"}, nil)
+	_, _ = sensutest.Run(check2, nil, nil, nil)
+	assert.Equal(t, "first", name1)
+	assert.Equal(t, "other default", name2)
+}
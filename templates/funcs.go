@@ -0,0 +1,457 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sensu/sensu-go/types"
+	"github.com/sensu/sensu-plugin-sdk/sensu"
+)
+
+// timeLayouts are the named layouts accepted by FormatTime, in addition to
+// layouts written with the reference time.
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+}
+
+// markdownEscaper escapes the characters with a meaning in markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `{`, `\{`, `}`, `\}`,
+	`[`, `\[`, `]`, `\]`, `(`, `\(`, `)`, `\)`, `#`, `\#`, `+`, `\+`,
+	`-`, `\-`, `.`, `\.`, `!`, `\!`, `|`, `\|`, `~`, `\~`, `>`, `\>`,
+)
+
+// slackEscaper escapes the control characters of Slack messages.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// FuncMap returns the functions available to templates. A new map is returned
+// on each call, so that it can be extended by the caller.
+//
+// The functions taking a string or a list take it as their last argument, so
+// that they can be used in pipelines, for example
+// {{ .Check.Output | Truncate 100 }}.
+//
+// Strings:
+//
+//	Lower, Upper, Title, Trim  change the case or trim the spaces of a string
+//	TrimPrefix, TrimSuffix     remove a prefix or a suffix: TrimPrefix "prefix" s
+//	Replace                    replace all occurrences: Replace "old" "new" s
+//	Contains, HasPrefix,       test a string: Contains "substr" s
+//	HasSuffix
+//	Split, Join                split or join strings: Join ", " list
+//	Truncate                   shorten a string to a number of characters,
+//	                           ending it with "..." when it is truncated
+//
+// Defaults:
+//
+//	Default  return the first argument if the second is empty: Default "n/a" v
+//	Empty    report whether a value is nil, zero or has no elements
+//
+// Times and durations, where times are a time.Time or a UNIX timestamp and
+// durations a time.Duration or a number of seconds:
+//
+//	UnixTime          convert a UNIX timestamp to a time.Time
+//	FormatTime        format a time with a layout written with the reference
+//	                  time, or the name of a time package layout such as
+//	                  "RFC3339": FormatTime "RFC3339" .Check.Executed
+//	InZone            convert a time to an IANA time zone: InZone "Europe/Paris" t
+//	Since             return the duration elapsed since a time
+//	Duration          convert a number of seconds to a time.Duration
+//	HumanizeDuration  format a duration with days, hours, minutes and seconds,
+//	                  for example "1d 2h 3m"
+//
+// Encoding and escaping:
+//
+//	ToJSON                     encode a value as JSON
+//	JSONEscape                 escape a string for a JSON string
+//	QueryEscape, PathEscape    escape a string for a URL query or path
+//	HTMLEscape                 escape a string for HTML
+//	MarkdownEscape             escape the markdown characters of a string
+//	SlackEscape                escape the control characters of Slack messages
+//
+// Maps and lists:
+//
+//	Keys    return the sorted keys of a map, such as .Entity.Labels
+//	HasKey  report whether a map has a key: HasKey .Entity.Labels "region"
+//	List    return a list of its arguments
+//	InList  report whether a list contains a value: InList "linux" list
+//
+// Events:
+//
+//	StatusName        return the name of a check status: StatusName .Check.Status
+//	Annotation        return an annotation of the check, or else of the entity:
+//	                  Annotation "runbook" .
+//	Label             return a label of the check, or else of the entity
+//	CheckAnnotation,  return an annotation or a label of the check or of the
+//	CheckLabel,       entity: EntityLabel "region" .
+//	EntityAnnotation,
+//	EntityLabel
+//	UUIDFromBytes     convert the bytes of an event ID to a UUID
+//	Hostname          return the host name of the system
+//
+// The annotation and label functions return an empty string when the event
+// has no check or entity, or when they don't find their key, unless the
+// template is strict.
+//
+// In HTML templates, HTMLEscape returns HTML which is not escaped again, and
+// SafeHTML and SafeURL mark trusted strings as HTML or as a URL, which are
+// written as they are.
+func FuncMap() template.FuncMap {
+	funcs := template.FuncMap{
+		"Lower":      strings.ToLower,
+		"Upper":      strings.ToUpper,
+		"Title":      strings.Title,
+		"Trim":       strings.TrimSpace,
+		"TrimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"TrimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"Replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+		"Contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"HasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"HasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"Split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"Join":       join,
+		"Truncate":   truncate,
+
+		"Default": func(def, value interface{}) interface{} {
+			if empty(value) {
+				return def
+			}
+			return value
+		},
+		"Empty": empty,
+
+		"UnixTime":         func(i int64) time.Time { return time.Unix(i, 0) },
+		"FormatTime":       formatTime,
+		"InZone":           inZone,
+		"Since":            since,
+		"Duration":         toDuration,
+		"HumanizeDuration": humanizeDuration,
+
+		"ToJSON":         toJSON,
+		"JSONEscape":     jsonEscape,
+		"QueryEscape":    url.QueryEscape,
+		"PathEscape":     url.PathEscape,
+		"HTMLEscape":     template.HTMLEscapeString,
+		"MarkdownEscape": markdownEscaper.Replace,
+		"SlackEscape":    slackEscaper.Replace,
+
+		"Keys":   keys,
+		"HasKey": hasKey,
+		"List":   func(values ...interface{}) []interface{} { return values },
+		"InList": inList,
+
+		"StatusName":    statusName,
+		"UUIDFromBytes": uuid.FromBytes,
+		"Hostname":      os.Hostname,
+	}
+	for name, f := range metadataFuncMap(false) {
+		funcs[name] = f
+	}
+	return funcs
+}
+
+// metadataFuncMap returns the annotation and label functions. When strict is
+// true, the functions return an error if they don't find their key.
+func metadataFuncMap(strict bool) template.FuncMap {
+	return template.FuncMap{
+		"Annotation":       metadataFunc(strict, "annotation", true, true),
+		"CheckAnnotation":  metadataFunc(strict, "annotation", true, false),
+		"EntityAnnotation": metadataFunc(strict, "annotation", false, true),
+		"Label":            metadataFunc(strict, "label", true, true),
+		"CheckLabel":       metadataFunc(strict, "label", true, false),
+		"EntityLabel":      metadataFunc(strict, "label", false, true),
+	}
+}
+
+// metadataFunc returns a function looking up a key in the annotations or the
+// labels of the check and of the entity of an event, in that order. Events
+// without a check or an entity are handled as if they had no annotations or
+// labels.
+func metadataFunc(strict bool, field string, check, entity bool) func(string, *types.Event) (string, error) {
+	return func(key string, event *types.Event) (string, error) {
+		var metas []types.ObjectMeta
+		if event != nil && check && event.Check != nil {
+			metas = append(metas, event.Check.ObjectMeta)
+		}
+		if event != nil && entity && event.Entity != nil {
+			metas = append(metas, event.Entity.ObjectMeta)
+		}
+		for _, meta := range metas {
+			values := meta.Annotations
+			if field == "label" {
+				values = meta.Labels
+			}
+			if value, ok := values[key]; ok {
+				return value, nil
+			}
+		}
+		if !strict {
+			return "", nil
+		}
+		switch {
+		case check && entity:
+			return "", fmt.Errorf("%s %q not found in check or entity", field, key)
+		case check:
+			return "", fmt.Errorf("%s %q not found in check", field, key)
+		default:
+			return "", fmt.Errorf("%s %q not found in entity", field, key)
+		}
+	}
+}
+
+// join joins the elements of a list, formatted with fmt.Sprint, with sep.
+func join(sep string, list interface{}) (string, error) {
+	if strs, ok := list.([]string); ok {
+		return strings.Join(strs, sep), nil
+	}
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("can't join %T", list)
+	}
+	strs := make([]string, v.Len())
+	for i := range strs {
+		strs[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(strs, sep), nil
+}
+
+// truncate shortens s to length characters, replacing its end with "..."
+// when it is truncated.
+func truncate(length int, s string) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	if length <= 3 {
+		return string(runes[:length])
+	}
+	return string(runes[:length-3]) + "..."
+}
+
+// empty reports whether value is nil, the zero value of its type, or a
+// string, slice or map without elements.
+func empty(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return reflect.DeepEqual(value, reflect.Zero(v.Type()).Interface())
+}
+
+// toInt64 converts an integer or a float to an int64.
+func toInt64(value interface{}) (int64, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return int64(v.Float()), nil
+	}
+	return 0, fmt.Errorf("expected a number, got %T", value)
+}
+
+// toTime converts a time.Time or a UNIX timestamp to a time.Time.
+func toTime(value interface{}) (time.Time, error) {
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+	i, err := toInt64(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a time or a timestamp, got %T", value)
+	}
+	return time.Unix(i, 0), nil
+}
+
+// toDuration converts a time.Duration or a number of seconds to a
+// time.Duration.
+func toDuration(value interface{}) (time.Duration, error) {
+	switch d := value.(type) {
+	case time.Duration:
+		return d, nil
+	case float32:
+		return time.Duration(float64(d) * float64(time.Second)), nil
+	case float64:
+		return time.Duration(d * float64(time.Second)), nil
+	}
+	i, err := toInt64(value)
+	if err != nil {
+		return 0, fmt.Errorf("expected a duration or a number of seconds, got %T", value)
+	}
+	return time.Duration(i) * time.Second, nil
+}
+
+// formatTime formats a time with a layout, which is either the name of one of
+// the layouts of the time package or a layout written with the reference time.
+func formatTime(layout string, value interface{}) (string, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return "", err
+	}
+	if named, ok := timeLayouts[layout]; ok {
+		layout = named
+	}
+	return t.Format(layout), nil
+}
+
+// inZone converts a time to the named IANA time zone.
+func inZone(zone string, value interface{}) (time.Time, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(location), nil
+}
+
+// since returns the duration elapsed since a time.
+func since(value interface{}) (time.Duration, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(t), nil
+}
+
+// humanizeDuration formats a duration with days, hours, minutes and seconds,
+// omitting the units equal to zero. Durations under a second are formatted
+// with time.Duration.String.
+func humanizeDuration(value interface{}) (string, error) {
+	d, err := toDuration(value)
+	if err != nil {
+		return "", err
+	}
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	if d < time.Second {
+		return sign + d.String(), nil
+	}
+	d = d.Round(time.Second)
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+	var parts []string
+	for _, unit := range units {
+		if n := d / unit.size; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, unit.suffix))
+			d -= n * unit.size
+		}
+	}
+	return sign + strings.Join(parts, " "), nil
+}
+
+// toJSON encodes a value as JSON, without escaping HTML characters.
+func toJSON(value interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// jsonEscape escapes a string to be written inside a JSON string.
+func jsonEscape(s string) (string, error) {
+	encoded, err := toJSON(s)
+	if err != nil {
+		return "", err
+	}
+	return encoded[1 : len(encoded)-1], nil
+}
+
+// keys returns the sorted keys of a map, formatted with fmt.Sprint.
+func keys(m interface{}) ([]string, error) {
+	v := reflect.ValueOf(m)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.Kind() != reflect.Map {
+		return nil, fmt.Errorf("expected a map, got %T", m)
+	}
+	keys := make([]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		keys = append(keys, fmt.Sprint(key.Interface()))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// hasKey reports whether the map m has the given key.
+func hasKey(m interface{}, key string) (bool, error) {
+	v := reflect.ValueOf(m)
+	if !v.IsValid() {
+		return false, nil
+	}
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return false, fmt.Errorf("expected a map with string keys, got %T", m)
+	}
+	return v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())).IsValid(), nil
+}
+
+// inList reports whether list contains value.
+func inList(value interface{}, list interface{}) (bool, error) {
+	v := reflect.ValueOf(list)
+	if !v.IsValid() {
+		return false, nil
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false, fmt.Errorf("expected a list, got %T", list)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if reflect.DeepEqual(v.Index(i).Interface(), value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// statusName returns the name of a check status, such as "OK" or "CRITICAL".
+func statusName(status interface{}) (string, error) {
+	i, err := toInt64(status)
+	if err != nil {
+		return "", err
+	}
+	return sensu.CheckStateName(int(i)), nil
+}
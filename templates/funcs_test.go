@@ -0,0 +1,106 @@
+package templates
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuncMap(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+	event.Entity.Labels = map[string]string{"region": "us-west-1", "app": "nginx"}
+	event.Check.Output = "CRITICAL: <b>500</b> & \"error\""
+
+	executed := time.Unix(event.Check.Executed, 0)
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"Lower", `{{ Lower "WebServer" }}`, "webserver"},
+		{"Upper", `{{ .Entity.Name | Upper }}`, "WEBSERVER01"},
+		{"Title", `{{ Title "check nginx" }}`, "Check Nginx"},
+		{"Trim", `{{ Trim "  output \n" }}`, "output"},
+		{"TrimPrefix", `{{ .Check.Name | TrimPrefix "check-" }}`, "nginx"},
+		{"TrimSuffix", `{{ .Check.Name | TrimSuffix "-nginx" }}`, "check"},
+		{"Replace", `{{ .Check.Name | Replace "-" "_" }}`, "check_nginx"},
+		{"Contains", `{{ .Check.Output | Contains "CRITICAL" }}`, "true"},
+		{"HasPrefix", `{{ .Entity.Name | HasPrefix "web" }}`, "true"},
+		{"HasSuffix", `{{ .Entity.Name | HasSuffix "web" }}`, "false"},
+		{"Split", `{{ index (.Check.Name | Split "-") 1 }}`, "nginx"},
+		{"Join", `{{ .Entity.Subscriptions | Join ", " }}`, "testing, entity:webserver01"},
+		{"Join list", `{{ List 1 2 3 | Join "+" }}`, "1+2+3"},
+		{"Truncate", `{{ .Entity.Name | Truncate 6 }}`, "web..."},
+		{"Truncate short", `{{ .Entity.Name | Truncate 20 }}`, "webserver01"},
+		{"Truncate tiny", `{{ .Entity.Name | Truncate 2 }}`, "we"},
+
+		{"Default", `{{ .Check.ProxyEntityName | Default "none" }}`, "none"},
+		{"Default set", `{{ .Entity.Name | Default "none" }}`, "webserver01"},
+		{"Empty", `{{ Empty .Check.Handlers }} {{ Empty .Check.RuntimeAssets }} {{ Empty 0 }}`, "false true true"},
+
+		{"FormatTime", `{{ FormatTime "2006-01-02 15:04" .Check.Executed }}`, executed.Format("2006-01-02 15:04")},
+		{"FormatTime named", `{{ FormatTime "RFC3339" .Check.Executed }}`, executed.Format(time.RFC3339)},
+		{"InZone", `{{ InZone "UTC" .Check.Executed | FormatTime "15:04 MST" }}`, executed.UTC().Format("15:04 MST")},
+		{"Since", `{{ if gt (Since .Check.Executed).Hours 1.0 }}old{{ end }}`, "old"},
+		{"Duration", `{{ Duration 90 }}`, "1m30s"},
+		{"HumanizeDuration", `{{ HumanizeDuration 93784 }}`, "1d 2h 3m 4s"},
+		{"HumanizeDuration float", `{{ .Check.Duration | HumanizeDuration }}`, "10.849143ms"},
+		{"HumanizeDuration zero", `{{ HumanizeDuration 7200 }} {{ HumanizeDuration 0 }}`, "2h 0s"},
+
+		{"ToJSON", `{{ ToJSON .Entity.Labels }}`, `{"app":"nginx","region":"us-west-1"}`},
+		{"JSONEscape", `"{{ JSONEscape .Check.Output }}"`, `"CRITICAL: <b>500</b> & \"error\""`},
+		{"QueryEscape", `{{ QueryEscape "a b&c" }}`, "a+b%26c"},
+		{"PathEscape", `{{ PathEscape "a b/c" }}`, "a%20b%2Fc"},
+		{"HTMLEscape", `{{ HTMLEscape .Check.Output }}`, "CRITICAL: &lt;b&gt;500&lt;/b&gt; &amp; &#34;error&#34;"},
+		{"MarkdownEscape", `{{ MarkdownEscape "*bold* [link](url) check_nginx" }}`, `\*bold\* \[link\]\(url\) check\_nginx`},
+		{"SlackEscape", `{{ SlackEscape .Check.Output }}`, "CRITICAL: &lt;b&gt;500&lt;/b&gt; &amp; \"error\""},
+
+		{"Keys", `{{ .Entity.Labels | Keys | Join "," }}`, "app,region"},
+		{"HasKey", `{{ HasKey .Entity.Labels "region" }} {{ HasKey .Entity.Labels "zone" }}`, "true false"},
+		{"InList", `{{ .Entity.Subscriptions | InList "testing" }} {{ .Check.Handlers | InList "email" }}`, "true false"},
+
+		{"StatusName", `{{ StatusName .Check.Status }} {{ StatusName 2 }} {{ StatusName 127 }}`, "WARNING CRITICAL UNKNOWN"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := EvalTemplate(test.name, test.template, event)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, result)
+		})
+	}
+}
+
+func TestFuncMapErrors(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{"Join", `{{ Join "," .Entity.Name }}`},
+		{"FormatTime", `{{ FormatTime "RFC3339" .Entity.Name }}`},
+		{"InZone", `{{ InZone "Nowhere/Unknown" .Check.Executed }}`},
+		{"HumanizeDuration", `{{ HumanizeDuration "long" }}`},
+		{"Keys", `{{ Keys .Entity.Subscriptions }}`},
+		{"InList", `{{ InList "testing" .Entity.Name }}`},
+		{"StatusName", `{{ StatusName "critical" }}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := EvalTemplate(test.name, test.template, event)
+			assert.Error(t, err)
+			assert.Equal(t, "", result)
+		})
+	}
+}
+
+func TestFuncMapIsExtensible(t *testing.T) {
+	funcs := FuncMap()
+	funcs["Lower"] = nil
+	assert.NotNil(t, FuncMap()["Lower"])
+}
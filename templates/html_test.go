@@ -0,0 +1,76 @@
+package templates
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvalHTMLTemplate(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+	event.Check.Output = `<script>alert("x")</script> & more`
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"escaped", `<p>{{ .Check.Output }}</p>`, `<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; more</p>`},
+		{"functions", `<b>{{ .Entity.Name | Upper }}</b> {{ StatusName .Check.Status }}`, `<b>WEBSERVER01</b> WARNING`},
+		{"HTMLEscape", `<p>{{ HTMLEscape .Check.Output }}</p>`, `<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; more</p>`},
+		{"SafeHTML", `<p>{{ SafeHTML "<i>trusted</i>" }}</p>`, `<p><i>trusted</i></p>`},
+		{"attribute", `<a title="{{ .Check.Output }}">`, `<a title="&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; more">`},
+		{"URL", `<a href="https://example.com/checks?name={{ .Check.Name }}&amp;q={{ .Check.Output }}">`, `<a href="https://example.com/checks?name=check-nginx&amp;q=%3cscript%3ealert%28%22x%22%29%3c%2fscript%3e%20%26%20more">`},
+		{"unsafe URL", `<a href="{{ "javascript:void" }}">`, `<a href="#ZgotmplZ">`},
+		{"SafeURL", `<a href="{{ SafeURL "javascript:void" }}">`, `<a href="javascript:void">`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := EvalHTMLTemplate(test.name, test.template, event)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, result)
+		})
+	}
+
+	_, err := EvalHTMLTemplate("nil", "<p>{{ .Check.Name }}</p>", nil)
+	assert.Error(t, err)
+	_, err = EvalHTMLTemplate("invalid", templateInvalid, event)
+	assert.Error(t, err)
+}
+
+func TestHTMLSource(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+	event.Check.Output = "a < b"
+
+	source, err := NewSource(SourceConfig{
+		Name:       "page",
+		Default:    `<p>{{ .Check.Output }}</p>{{ template "footer" . }}{{ define "footer" }}<hr>{{ end }}`,
+		Keyspace:   testKeyspace,
+		Annotation: "footer",
+		Options:    Options{HTML: true},
+	})
+	assert.NoError(t, err)
+
+	result, err := source.Render(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>a &lt; b</p><hr>", result)
+
+	// the annotations of events are parsed after the template was executed
+	event.Check.Annotations[testKeyspace+"/footer"] = `{{ define "footer" }}<small>{{ .Entity.Name }} & co</small>{{ end }}`
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := source.Render(event)
+			assert.NoError(t, err)
+			assert.Equal(t, "<p>a &lt; b</p><small>webserver01 & co</small>", result)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,129 @@
+package templates
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/sensu/sensu-plugin-sdk/sensu"
+)
+
+// SourceConfig describes the sources of the text of a template. The sources
+// are layered: each source is parsed over the sources of lower precedence, and
+// replaces their text unless it only contains {{define}} blocks, which are
+// added to the partials of the template. From the highest to the lowest
+// precedence, the sources are:
+//
+//	the annotation of the event at Annotation in Keyspace
+//	Text, usually the value of a plugin option
+//	the file at File
+//	Default, the built-in template of the plugin
+//
+// Partials defined by any of the sources can be used with {{template}} by the
+// others, and can be redefined by a source of higher precedence.
+type SourceConfig struct {
+	// Name is the name of the template.
+	Name string
+
+	// Text is the text of the template.
+	Text string
+
+	// File is the path of a file containing the template.
+	File string
+
+	// Keyspace is the keyspace of the annotations of the plugin, usually the
+	// Keyspace of its PluginConfig.
+	Keyspace string
+
+	// Annotation is the path of the check or entity annotation containing the
+	// template, in Keyspace. The annotations of the check take precedence over
+	// the annotations of the entity.
+	Annotation string
+
+	// Default is the template used when no other source is set.
+	Default string
+
+	// Options are the options of the template.
+	Options Options
+}
+
+// Source resolves templates from the sources of a SourceConfig. The default,
+// file and text sources are parsed once by NewSource, and the annotations of
+// each event are parsed over them.
+type Source struct {
+	config SourceConfig
+	base   *Template
+	plain  *Template
+}
+
+// NewSource reads and parses the default, file and text sources of the
+// config. It returns an error if the file can't be read, if a source can't be
+// parsed, or if none of the sources are set.
+func NewSource(config SourceConfig) (*Source, error) {
+	fileText := ""
+	if config.File != "" {
+		b, err := ioutil.ReadFile(config.File)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template file: %s", err)
+		}
+		fileText = string(b)
+	}
+	if config.Default == "" && fileText == "" && config.Text == "" && config.Annotation == "" {
+		return nil, fmt.Errorf("no source for template %q", config.Name)
+	}
+
+	templ := config.Options.newTemplate(config.Name)
+	layers := []struct {
+		source string
+		text   string
+	}{
+		{"default", config.Default},
+		{"file " + config.File, fileText},
+		{"text", config.Text},
+	}
+	for _, layer := range layers {
+		if layer.text == "" {
+			continue
+		}
+		if err := templ.parse(layer.text); err != nil {
+			return nil, fmt.Errorf("error parsing template %q from %s: %s", config.Name, layer.source, err)
+		}
+	}
+	// the base template is never executed, so that it can be cloned for the
+	// annotations of events: HTML templates can't be cloned once executed.
+	plain, err := templ.clone()
+	if err != nil {
+		return nil, err
+	}
+	return &Source{config: config, base: templ, plain: plain}, nil
+}
+
+// Template returns the template for event: the template parsed by NewSource,
+// or a copy of it with the annotation of the event parsed over it. The
+// returned template may be shared between events and must not be modified.
+func (s *Source) Template(event *types.Event) (*Template, error) {
+	if s.config.Annotation == "" {
+		return s.plain, nil
+	}
+	annotation, ok := sensu.AnnotationOverride(event, s.config.Keyspace, s.config.Annotation)
+	if !ok {
+		return s.plain, nil
+	}
+	templ, err := s.base.clone()
+	if err != nil {
+		return nil, err
+	}
+	if err := templ.parse(annotation); err != nil {
+		return nil, fmt.Errorf("error parsing template %q from annotation %s: %s", s.config.Name, s.config.Annotation, err)
+	}
+	return templ, nil
+}
+
+// Render resolves the template for event and applies it to the event.
+func (s *Source) Render(event *types.Event) (string, error) {
+	templ, err := s.Template(event)
+	if err != nil {
+		return "", err
+	}
+	return templ.Render(event)
+}
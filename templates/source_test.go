@@ -0,0 +1,105 @@
+package templates
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKeyspace = "sensu.io/plugins/segp/config"
+
+func writeTemplateFile(t *testing.T, text string) string {
+	t.Helper()
+	f, err := ioutil.TempFile("", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestSourcePrecedence(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+
+	file := writeTemplateFile(t, "file: {{ .Check.Name }}")
+	defer os.Remove(file)
+
+	tests := []struct {
+		name   string
+		config SourceConfig
+		want   string
+	}{
+		{"default", SourceConfig{Default: "default: {{ .Check.Name }}"}, "default: check-nginx"},
+		{"file", SourceConfig{Default: "default", File: file}, "file: check-nginx"},
+		{"text", SourceConfig{Default: "default", File: file, Text: "text: {{ .Entity.Name }}"}, "text: webserver01"},
+		{"annotation", SourceConfig{Text: "text", Keyspace: testKeyspace, Annotation: "path1"}, "value-check1"},
+		{"missing annotation", SourceConfig{Text: "text", Keyspace: testKeyspace, Annotation: "missing"}, "text"},
+		{"no keyspace", SourceConfig{Text: "text", Annotation: "path1"}, "text"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.config.Name = test.name
+			source, err := NewSource(test.config)
+			assert.NoError(t, err)
+			result, err := source.Render(event)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, result)
+		})
+	}
+}
+
+func TestSourcePartials(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+	event.Check.Annotations[testKeyspace+"/body"] = `{{ define "footer" }}-- {{ .Entity.Name }}{{ end }}`
+
+	file := writeTemplateFile(t, `{{ define "header" }}[{{ .Check.State }}]{{ end }}
+`)
+	defer os.Remove(file)
+
+	source, err := NewSource(SourceConfig{
+		Name:       "body",
+		Default:    `{{ template "header" . }} {{ .Check.Output }} {{ template "footer" . }}{{ define "header" }}[default]{{ end }}{{ define "footer" }}--{{ end }}`,
+		File:       file,
+		Keyspace:   testKeyspace,
+		Annotation: "body",
+	})
+	assert.NoError(t, err)
+
+	result, err := source.Render(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "[failing] example output -- webserver01", result)
+
+	delete(event.Check.Annotations, testKeyspace+"/body")
+	result, err = source.Render(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "[failing] example output --", result)
+}
+
+func TestSourceErrors(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+
+	_, err := NewSource(SourceConfig{Name: "none"})
+	assert.Error(t, err)
+
+	_, err = NewSource(SourceConfig{Name: "file", File: "/nonexistent/template"})
+	assert.Error(t, err)
+
+	_, err = NewSource(SourceConfig{Name: "invalid", Default: "ok", Text: templateInvalid})
+	assert.Error(t, err)
+
+	event.Entity.Annotations[testKeyspace+"/body"] = templateInvalid
+	source, err := NewSource(SourceConfig{Name: "annotation", Text: "ok", Keyspace: testKeyspace, Annotation: "body"})
+	assert.NoError(t, err)
+	_, err = source.Render(event)
+	assert.Error(t, err)
+}
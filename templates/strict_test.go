@@ -0,0 +1,122 @@
+package templates
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sensu/sensu-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictMissingKey(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+	text := "Runbook:\n{{ .Check.Annotations.runbook }}"
+
+	templ, err := NewTemplate("lenient", text, Options{})
+	assert.NoError(t, err)
+	result, err := templ.Render(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "Runbook:\n<no value>", result)
+
+	for _, options := range []Options{{Strict: true}, {Strict: true, HTML: true}} {
+		templ, err = NewTemplate("strict", text, options)
+		assert.NoError(t, err)
+		_, err = templ.Render(event)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "strict:2:")
+			assert.Contains(t, err.Error(), `"runbook"`)
+		}
+		assert.Error(t, templ.Validate(event))
+	}
+
+	event.Check.Annotations["runbook"] = "https://wiki.example.com"
+	result, err = templ.Render(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "Runbook:\nhttps://wiki.example.com", result)
+}
+
+func TestMetadataFuncs(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+	event.Entity.Labels = map[string]string{"region": "us-west-1", "team": "web"}
+	event.Check.Labels = map[string]string{"team": "ops"}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"Annotation", `{{ Annotation "sensu.io/plugins/segp/config/path1" . }}`, "value-check1"},
+		{"CheckAnnotation", `{{ CheckAnnotation "sensu.io/plugins/segp/config/path2" . }}`, "1357"},
+		{"EntityAnnotation", `{{ EntityAnnotation "sensu.io/plugins/segp/config/path2" . }}`, "2468"},
+		{"Label", `{{ Label "team" . }} {{ Label "region" . }}`, "ops us-west-1"},
+		{"CheckLabel", `{{ CheckLabel "team" . }}`, "ops"},
+		{"EntityLabel", `{{ . | EntityLabel "team" }}`, "web"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, options := range []Options{{}, {Strict: true}} {
+				templ, err := NewTemplate(test.name, test.template, options)
+				assert.NoError(t, err)
+				result, err := templ.Render(event)
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, result)
+			}
+		})
+	}
+}
+
+func TestMetadataFuncsMissing(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+	noCheck := &types.Event{Entity: event.Entity}
+
+	tests := []struct {
+		name     string
+		template string
+		event    *types.Event
+		err      string
+	}{
+		{"Annotation", `{{ Annotation "runbook" . }}`, event, `annotation "runbook" not found in check or entity`},
+		{"CheckLabel", `{{ CheckLabel "team" . }}`, event, `label "team" not found in check`},
+		{"EntityLabel", `{{ EntityLabel "team" . }}`, event, `label "team" not found in entity`},
+		{"no check", `{{ CheckAnnotation "sensu.io/plugins/segp/config/path1" . }}`, noCheck, `annotation "sensu.io/plugins/segp/config/path1" not found in check`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			templ, err := NewTemplate(test.name, test.template, Options{})
+			assert.NoError(t, err)
+			result, err := templ.Render(test.event)
+			assert.NoError(t, err)
+			assert.Equal(t, "", result)
+
+			templ, err = NewTemplate(test.name, "\n"+test.template, Options{Strict: true})
+			assert.NoError(t, err)
+			_, err = templ.Render(test.event)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.name+":2:")
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
+func TestStrictSource(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+
+	source, err := NewSource(SourceConfig{
+		Name:    "strict",
+		Default: `{{ .Check.Name }}: {{ Annotation "runbook" . }}`,
+		Options: Options{Strict: true},
+	})
+	assert.NoError(t, err)
+	_, err = source.Render(event)
+	assert.Error(t, err)
+
+	event.Entity.Annotations["runbook"] = "https://wiki.example.com"
+	result, err := source.Render(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "check-nginx: https://wiki.example.com", result)
+}
@@ -0,0 +1,164 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"text/template"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+)
+
+// Options are the options of a Template.
+type Options struct {
+	// Funcs are functions added to the functions of FuncMap. A function with
+	// the name of one of the functions of FuncMap replaces it.
+	Funcs template.FuncMap
+
+	// HTML renders the template with html/template instead of text/template,
+	// escaping the values written by the template according to their context
+	// in the HTML document.
+	HTML bool
+
+	// Strict makes the execution of the template fail when it reads a key
+	// missing from a map, such as an annotation, instead of writing
+	// "<no value>", and when the annotation and label functions don't find
+	// their key. The error names the template, the line and the missing key.
+	Strict bool
+}
+
+// Template is a template parsed once, which can be executed many times,
+// including concurrently.
+type Template struct {
+	name string
+	text *template.Template
+	html *htmltemplate.Template
+}
+
+// newTemplate returns a new, empty template with the functions of FuncMap and
+// of the options.
+func (o Options) newTemplate(name string) *Template {
+	funcs := FuncMap()
+	if o.Strict {
+		for name, f := range metadataFuncMap(true) {
+			funcs[name] = f
+		}
+	}
+	if o.HTML {
+		for name, f := range htmlFuncMap() {
+			funcs[name] = f
+		}
+	}
+	for name, f := range o.Funcs {
+		funcs[name] = f
+	}
+	missingKey := "missingkey=default"
+	if o.Strict {
+		missingKey = "missingkey=error"
+	}
+	if o.HTML {
+		return &Template{name: name, html: htmltemplate.New(name).Funcs(htmltemplate.FuncMap(funcs)).Option(missingKey)}
+	}
+	return &Template{name: name, text: template.New(name).Funcs(funcs).Option(missingKey)}
+}
+
+// htmlFuncMap returns the functions replacing the functions of FuncMap in
+// HTML templates.
+func htmlFuncMap() template.FuncMap {
+	return template.FuncMap{
+		// HTMLEscape returns HTML, to not escape its result twice.
+		"HTMLEscape": func(s string) htmltemplate.HTML {
+			return htmltemplate.HTML(htmltemplate.HTMLEscapeString(s))
+		},
+		"SafeHTML": func(s string) htmltemplate.HTML { return htmltemplate.HTML(s) },
+		"SafeURL":  func(s string) htmltemplate.URL { return htmltemplate.URL(s) },
+	}
+}
+
+// NewTemplate parses text as a template named name, with the functions of
+// FuncMap and of the options.
+func NewTemplate(name, text string, options Options) (*Template, error) {
+	if len(text) == 0 {
+		return nil, fmt.Errorf("must pass in template")
+	}
+	templ := options.newTemplate(name)
+	if err := templ.parse(text); err != nil {
+		return nil, fmt.Errorf("Error building template: %s", err)
+	}
+	return templ, nil
+}
+
+// parse parses text over the template.
+func (t *Template) parse(text string) (err error) {
+	if t.html != nil {
+		_, err = t.html.Parse(text)
+	} else {
+		_, err = t.text.Parse(text)
+	}
+	return err
+}
+
+// clone returns a copy of the template, which can be parsed over without
+// modifying the template. HTML templates can't be cloned once executed.
+func (t *Template) clone() (*Template, error) {
+	if t.html != nil {
+		html, err := t.html.Clone()
+		if err != nil {
+			return nil, err
+		}
+		return &Template{name: t.name, html: html}, nil
+	}
+	text, err := t.text.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return &Template{name: t.name, text: text}, nil
+}
+
+// Name returns the name of the template.
+func (t *Template) Name() string {
+	return t.name
+}
+
+// Execute applies the template to data, usually an event, and writes the
+// output to w.
+func (t *Template) Execute(w io.Writer, data interface{}) error {
+	if data == nil {
+		return fmt.Errorf("must pass in template source")
+	}
+	var err error
+	if t.html != nil {
+		err = t.html.Execute(w, data)
+	} else {
+		err = t.text.Execute(w, data)
+	}
+	if err != nil {
+		return fmt.Errorf("Error executing template: %s", err)
+	}
+	return nil
+}
+
+// Render applies the template to data, usually an event, and returns the
+// output.
+func (t *Template) Render(data interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Validate executes the template with a sample and discards the output, to
+// check that the template applies to the data it will be executed with. Use it
+// in the validation function of a plugin, to report template errors before
+// events are processed. If sample is nil, a fixture event is used: strict
+// templates using annotations or labels should be validated with a sample
+// having them.
+func (t *Template) Validate(sample interface{}) error {
+	if sample == nil {
+		sample = corev2.FixtureEvent("entity1", "check1")
+	}
+	return t.Execute(ioutil.Discard, sample)
+}
@@ -0,0 +1,96 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+
+	corev2 "github.com/sensu/sensu-go/api/core/v2"
+	"github.com/sensu/sensu-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTemplate(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+
+	templ, err := NewTemplate("subject", templateOk, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, "subject", templ.Name())
+
+	result, err := templ.Render(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "Check: check-nginx Entity: webserver01 !", result)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, templ.Execute(buf, event))
+	assert.Equal(t, result, buf.String())
+
+	_, err = templ.Render(nil)
+	assert.Error(t, err)
+}
+
+func TestNewTemplateErrors(t *testing.T) {
+	_, err := NewTemplate("empty", "", Options{})
+	assert.Error(t, err)
+
+	_, err = NewTemplate("invalid", templateInvalid, Options{})
+	assert.Error(t, err)
+
+	_, err = NewTemplate("unknown", "{{ Shout .Check.Name }}", Options{})
+	assert.Error(t, err)
+}
+
+func TestNewTemplateFuncs(t *testing.T) {
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+
+	funcs := template.FuncMap{
+		"Shout": func(s string) string { return strings.ToUpper(s) + "!" },
+		"Lower": func(s string) string { return "lower:" + s },
+	}
+	templ, err := NewTemplate("funcs", "{{ Shout .Check.Name }} {{ Lower .Entity.Name }} {{ Upper .Entity.Name }}", Options{Funcs: funcs})
+	assert.NoError(t, err)
+
+	result, err := templ.Render(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "CHECK-NGINX! lower:webserver01 WEBSERVER01", result)
+
+	_, ok := FuncMap()["Shout"]
+	assert.False(t, ok)
+}
+
+func TestTemplateValidate(t *testing.T) {
+	templ, err := NewTemplate("valid", templateOk, Options{})
+	assert.NoError(t, err)
+	assert.NoError(t, templ.Validate(nil))
+
+	event := &types.Event{}
+	_ = json.Unmarshal(testEventBytes, event)
+	assert.NoError(t, templ.Validate(event))
+
+	templ, err = NewTemplate("notfound", templateVarNotFound, Options{})
+	assert.NoError(t, err)
+	assert.Error(t, templ.Validate(nil))
+}
+
+func TestTemplateConcurrentRender(t *testing.T) {
+	templ, err := NewTemplate("concurrent", "{{ .Entity.Name | Upper }}/{{ .Check.Name }}", Options{})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			event := corev2.FixtureEvent("entity"+string(rune('a'+i)), "check")
+			result, err := templ.Render(event)
+			assert.NoError(t, err)
+			assert.Equal(t, "ENTITY"+string(rune('A'+i))+"/check", result)
+		}(i)
+	}
+	wg.Wait()
+}
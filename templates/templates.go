@@ -1,36 +1,32 @@
 package templates
 
 import (
-	"bytes"
 	"fmt"
-	"github.com/google/uuid"
-	"os"
-	"text/template"
-	"time"
 )
 
+// EvalTemplate parses templStr and applies it to templSrc, usually an event.
+// Use a Template to parse a template once and execute it many times.
 func EvalTemplate(templName, templStr string, templSrc interface{}) (string, error) {
 	if templSrc == nil {
 		return "", fmt.Errorf("must pass in template source")
 	}
-	if len(templStr) == 0 {
-		return "", fmt.Errorf("must pass in template")
-	}
-
-	templ, err := template.New(templName).Funcs(template.FuncMap{
-		"UnixTime":      func(i int64) time.Time { return time.Unix(i, 0) },
-		"UUIDFromBytes": uuid.FromBytes,
-		"Hostname":      os.Hostname,
-	}).Parse(templStr)
+	templ, err := NewTemplate(templName, templStr, Options{})
 	if err != nil {
-		return "", fmt.Errorf("Error building template: %s", err)
+		return "", err
 	}
+	return templ.Render(templSrc)
+}
 
-	buf := new(bytes.Buffer)
-	err = templ.Execute(buf, templSrc)
+// EvalHTMLTemplate parses templStr as an HTML template and applies it to
+// templSrc, usually an event. The values written by the template are escaped
+// according to their context in the HTML document.
+func EvalHTMLTemplate(templName, templStr string, templSrc interface{}) (string, error) {
+	if templSrc == nil {
+		return "", fmt.Errorf("must pass in template source")
+	}
+	templ, err := NewTemplate(templName, templStr, Options{HTML: true})
 	if err != nil {
-		return "", fmt.Errorf("Error executing template: %s", err)
+		return "", err
 	}
-
-	return buf.String(), nil
+	return templ.Render(templSrc)
 }